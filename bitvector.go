@@ -0,0 +1,161 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boolrecognition
+
+import "math/bits"
+
+// BitVector is a point (a vector of Boolean values) that stores its values
+// bit-packed, so it requires only one bit for each variable (BooleanVector
+// requires one byte).
+//
+// The values are stored s.t. comparing the words as integers compares the
+// vectors lexicographically: Variable i is stored in word i / 64 in bit
+// 63 - i % 64, so variable 0 is the most significant bit. Unused bits in the
+// last word are always 0.
+type BitVector struct {
+	size  int
+	words []uint64
+}
+
+// NewBitVector returns a new bit vector with size entries, all set to false.
+func NewBitVector(size int) *BitVector {
+	return &BitVector{size: size, words: make([]uint64, (size+63)/64)}
+}
+
+// NewBitVectorFrom returns a new bit vector with the values of the Boolean
+// vector.
+func NewBitVectorFrom(vector BooleanVector) *BitVector {
+	res := NewBitVector(len(vector))
+	for i, val := range vector {
+		if val {
+			res.Set(i)
+		}
+	}
+	return res
+}
+
+// bitMask returns the word and the mask for the entry i.
+func bitMask(i int) (int, uint64) {
+	return i / 64, 1 << (63 - uint(i%64))
+}
+
+// Len returns the number of entries in the vector.
+func (v *BitVector) Len() int {
+	return v.size
+}
+
+// Test returns the value of entry i.
+func (v *BitVector) Test(i int) bool {
+	word, mask := bitMask(i)
+	return v.words[word]&mask != 0
+}
+
+// Set sets entry i to true.
+func (v *BitVector) Set(i int) {
+	word, mask := bitMask(i)
+	v.words[word] |= mask
+}
+
+// Clear sets entry i to false.
+func (v *BitVector) Clear(i int) {
+	word, mask := bitMask(i)
+	v.words[word] &^= mask
+}
+
+// Clone returns a new bit vector initialized with the contents of this
+// vector.
+func (v *BitVector) Clone() *BitVector {
+	words := make([]uint64, len(v.words))
+	copy(words, v.words)
+	return &BitVector{size: v.size, words: words}
+}
+
+// Count returns the number of entries that are true.
+func (v *BitVector) Count() int {
+	res := 0
+	for _, word := range v.words {
+		res += bits.OnesCount64(word)
+	}
+	return res
+}
+
+// NextSet returns the smallest index j ≥ i s.t. entry j is true. If there is
+// no such entry it returns -1.
+// So all true entries can be iterated with
+//
+//	for j := v.NextSet(0); j >= 0; j = v.NextSet(j + 1) {...}
+func (v *BitVector) NextSet(i int) int {
+	if i >= v.size {
+		return -1
+	}
+	word, mask := bitMask(i)
+	// mask all bits before i
+	w := v.words[word] & (mask | (mask - 1))
+	for {
+		if w != 0 {
+			return word*64 + bits.LeadingZeros64(w)
+		}
+		word++
+		if word == len(v.words) {
+			return -1
+		}
+		w = v.words[word]
+	}
+}
+
+// Compare compares two vectors of the same length lexicographically (false
+// is smaller than true). It returns 0 if both vectors are equal, a value < 0
+// if v is smaller and a value > 0 if v is greater.
+func (v *BitVector) Compare(other *BitVector) int {
+	for i, word := range v.words {
+		switch otherWord := other.words[i]; {
+		case word < otherWord:
+			return -1
+		case word > otherWord:
+			return 1
+		}
+	}
+	return 0
+}
+
+// FirstDifference returns the smallest index i s.t. entry i in v is not
+// equal to entry i in other (both vectors must have the same length). If the
+// vectors are equal it returns -1.
+func (v *BitVector) FirstDifference(other *BitVector) int {
+	for i, word := range v.words {
+		if diff := word ^ other.words[i]; diff != 0 {
+			return i*64 + bits.LeadingZeros64(diff)
+		}
+	}
+	return -1
+}
+
+// Equals checks if both vectors have the same length and values.
+func (v *BitVector) Equals(other *BitVector) bool {
+	return v.size == other.size && v.FirstDifference(other) < 0
+}
+
+// ToBooleanVector returns the values of the vector as a BooleanVector.
+func (v *BitVector) ToBooleanVector() BooleanVector {
+	res := NewBooleanVector(v.size)
+	for i := v.NextSet(0); i >= 0; i = v.NextSet(i + 1) {
+		res[i] = true
+	}
+	return res
+}
+
+func (v *BitVector) String() string {
+	return v.ToBooleanVector().String()
+}
@@ -16,13 +16,15 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"strings"
 	"time"
 
-	br "github.com/FabianWe/boolrecognition"
 	"github.com/FabianWe/boolrecognition/lpb"
 )
 
@@ -49,25 +51,53 @@ const (
 
 func main() {
 	tighten := lpb.TightenNone
-	lpbFileFlag := flag.String("lpb", "", "Path to the lpb file")
-	verify := flag.Bool("verify", false, "If true also verify that the produced LPB is correct")
-	solverType := flag.String("solver", "minComb", "The solver to use, currently \"mincomb\" and \"lp\" are available")
+	lpbFileFlag := flag.String("lpb", "", "Path to the lpb file, files ending with .opb are parsed in OPB format")
+	dnfFlag := flag.String("dnf", "", "Path to a DIMACS file, a directory of DIMACS files or a glob pattern, the files must"+
+		" contain positive DNFs. Use this instead of -lpb to also test DNFs that are not threshold functions")
+	verify := flag.Bool("verify", false, "If true also verify that the produced LPB (or the certificate if the DNF is not"+
+		" a threshold function) is correct")
+	solverType := flag.String("solver", "minComb", "The solver to use, currently \"minComb\", \"lp\", \"chow\" and \"hybrid\""+
+		" (combinatorial solver with lp solver as fallback) are available")
 	numberLoops := flag.Int("N", 5, "The number of times you want to repeat each conversion")
 	repeat := flag.Int("R", 3, "How many times to repeat the conversions N times? Best value will be used")
 	tightenFlag := flag.String("tighten", "none", "If the solver is lp solver this describes how to tighten the lp:"+
 		" \"none\" for now additional constraints, \"neighbours\" for constraints v(i) and v(i + 1) and \"all\""+
 		" for constraints between all v(i) and v(j). Default is \"none\"")
+	backendFlag := flag.String("backend", "simplex", "If the solver is lp solver this describes the lp backend to use:"+
+		" \"simplex\" for the pure Go solver and \"golp\" for lpsolve (only if built with the golp tag)")
+	exactFlag := flag.Bool("exact", true, "If the solver is lp solver solve the lp with exact rational arithmetic instead"+
+		" of integer constraints, ignored for backends that don't support it (golp)")
+	objectiveFlag := flag.String("objective", "none", "If the solver is lp solver this describes what to minimize:"+
+		" \"none\" for no objective, \"threshold\" for the threshold, \"sum\" for the sum of all coefficients"+
+		" and \"max\" for the greatest coefficient")
+	timeout := flag.Duration("timeout", 0, "Timeout for each single conversion (for example \"10s\"), conversions that time out"+
+		" count as failed. 0 means no timeout")
+	workers := flag.Int("j", 1, "The number of conversions to run in parallel, each worker uses its own solver")
+	format := flag.String("format", "text", "The output format: \"text\" for a summary, \"json\" or \"csv\" for one record"+
+		" per LPB and the percentiles of the durations")
 	flag.Parse()
+	// the information about the solver is only printed in text mode
+	var info io.Writer = os.Stdout
+	switch *format {
+	case "text":
+	case "json", "csv":
+		info = ioutil.Discard
+	default:
+		fmt.Fprintln(os.Stderr, "Format must be either \"text\", \"json\" or \"csv\", got", *format)
+		os.Exit(1)
+	}
 	var converter lpb.DNFToLPB
-	if *lpbFileFlag == "" {
-		fmt.Fprintln(os.Stderr, "lpb must be provided and must point to the file containg all the LPBs")
+	// the names of the solvers if converter is a hybrid solver
+	var hybridNames []string
+	if (*lpbFileFlag == "") == (*dnfFlag == "") {
+		fmt.Fprintln(os.Stderr, "Either lpb must point to the file containg all the LPBs or dnf must point to the DIMACS files")
 		os.Exit(1)
 	}
 	switch *solverType {
 	case "minComb":
 		converter = lpb.NewCombinatorialSolver(lpb.NewMinSolver())
-		fmt.Println("Using combinatorial solver with minimum chooser")
-		fmt.Println()
+		fmt.Fprintln(info, "Using combinatorial solver with minimum chooser")
+		fmt.Fprintln(info)
 	case "lp":
 		switch *tightenFlag {
 		case "none":
@@ -79,11 +109,40 @@ func main() {
 			fmt.Fprintln(os.Stderr, "Tighten type must be either \"none\", \"neighbours\" or \"all\", got", *tightenFlag)
 			os.Exit(1)
 		}
-		converter = lpb.NewLPSolver(tighten)
-		fmt.Println("Using linear program solver with tighten option", *tightenFlag)
-		fmt.Println()
+		backend, backendOk := lpb.LPBackends[*backendFlag]
+		if !backendOk {
+			fmt.Fprintln(os.Stderr, "Unknown lp backend", *backendFlag)
+			os.Exit(1)
+		}
+		lpSolver := lpb.NewLPSolver(tighten)
+		lpSolver.Backend = backend
+		lpSolver.Exact = *exactFlag
+		switch *objectiveFlag {
+		case "none":
+		case "threshold":
+			lpSolver.Objective = lpb.ObjectiveThreshold
+		case "sum":
+			lpSolver.Objective = lpb.ObjectiveSum
+		case "max":
+			lpSolver.Objective = lpb.ObjectiveMax
+		default:
+			fmt.Fprintln(os.Stderr, "Objective must be either \"none\", \"threshold\", \"sum\" or \"max\", got", *objectiveFlag)
+			os.Exit(1)
+		}
+		converter = lpSolver
+		fmt.Fprintln(info, "Using linear program solver with tighten option", *tightenFlag, "and backend", *backendFlag)
+		fmt.Fprintln(info)
+	case "chow":
+		converter = lpb.NewChowSolver()
+		fmt.Fprintln(info, "Using Chow parameter solver")
+		fmt.Fprintln(info)
+	case "hybrid":
+		converter = lpb.NewHybridSolver()
+		hybridNames = []string{"minComb", "lp"}
+		fmt.Fprintln(info, "Using hybrid solver: combinatorial solver with minimum chooser, linear program solver as fallback")
+		fmt.Fprintln(info)
 	default:
-		fmt.Fprintln(os.Stderr, "Only \"minComb\" and \"lp\" are valid solvers, got", *solverType)
+		fmt.Fprintln(os.Stderr, "Only \"minComb\", \"lp\", \"chow\" and \"hybrid\" are valid solvers, got", *solverType)
 		os.Exit(1)
 	}
 	if *numberLoops <= 0 {
@@ -94,19 +153,43 @@ func main() {
 		fmt.Fprintln(os.Stderr, "R must be > 0")
 		os.Exit(1)
 	}
-	lpbs, dnfs, parseErr := parseLPBs(*lpbFileFlag)
-	var numFailedConv, numNotEqual int
+	if *workers <= 0 {
+		fmt.Fprintln(os.Stderr, "j must be > 0")
+		os.Exit(1)
+	}
+	var inputs []input
+	var failures []parseFailure
+	var parseErr error
+	if *dnfFlag != "" {
+		inputs, failures, parseErr = parseDNFs(*dnfFlag)
+	} else {
+		inputs, parseErr = parseLPBs(*lpbFileFlag)
+	}
+	var numFailedConv, numNotEqual, numTimeouts int
+	var solverCounts []int
 	bestSoFarSucc := -1.0
 	bestSoFarAll := -1.0
 	if parseErr != nil {
-		fmt.Fprintln(os.Stderr, "Error parsing LPBs:", parseErr)
+		fmt.Fprintln(os.Stderr, "Error parsing inputs:", parseErr)
 		os.Exit(1)
 	}
+	if *format != "text" {
+		for _, failure := range failures {
+			fmt.Fprintf(os.Stderr, "Error parsing %s: %v\n", failure.path, failure.err)
+		}
+	}
+	records := make([]*record, len(inputs))
+	for i, in := range inputs {
+		records[i] = &record{Index: i, Name: in.name, Nbvar: in.nbvar, Clauses: len(in.phi)}
+	}
+	report := func(res lpb.Result, verified *bool) {
+		records[res.Index].update(res, *solverType, hybridNames, verified)
+	}
 	for i := 0; i < *repeat; i++ {
 		// repeat the test, get average
 		var avgSucc, avgAll float64
 		// run verify only in the last run, no need to always do it
-		avgSucc, avgAll, numFailedConv, numNotEqual = runTest(lpbs, dnfs, *numberLoops, *verify && (i == *repeat-1), converter)
+		avgSucc, avgAll, numFailedConv, numNotEqual, numTimeouts, solverCounts = runTest(inputs, *numberLoops, *verify && (i == *repeat-1), converter, *timeout, *workers, report)
 		if bestSoFarSucc < 0 || avgSucc < bestSoFarSucc {
 			bestSoFarSucc = avgSucc
 		}
@@ -114,70 +197,122 @@ func main() {
 			bestSoFarAll = avgAll
 		}
 	}
+	switch *format {
+	case "json":
+		if err := writeJSON(os.Stdout, records); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing JSON:", err)
+			os.Exit(1)
+		}
+		return
+	case "csv":
+		if err := writeCSV(os.Stdout, records); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing CSV:", err)
+			os.Exit(1)
+		}
+		// the CSV contains only records, the summary goes to stderr
+		fmt.Fprintln(os.Stderr, "Summary:", summarize(records))
+		return
+	}
 	// print evaluation
 	fmt.Printf("Ran tests %d times, showing best average of %d repeats\n\n", *numberLoops, *repeat)
-	failRate := (float64(numFailedConv) / float64(len(lpbs))) * 100.0
-	fmt.Printf("Conversion failed on %d of %d tests (%.2f%%)\n", numFailedConv, len(lpbs), failRate)
+	failRate := (float64(numFailedConv) / float64(len(inputs))) * 100.0
+	fmt.Printf("Conversion failed on %d of %d tests (%.2f%%)\n", numFailedConv, len(inputs), failRate)
+	if *timeout > 0 {
+		timeoutRate := (float64(numTimeouts) / float64(len(inputs))) * 100.0
+		fmt.Printf("Conversion timed out after %s on %d of %d tests (%.2f%%)\n", *timeout, numTimeouts, len(inputs), timeoutRate)
+	}
 	if *verify {
-		errorRate := (float64(numNotEqual) / float64(len(lpbs)-numFailedConv)) * 100.0
+		errorRate := (float64(numNotEqual) / float64(len(inputs)-numFailedConv)) * 100.0
 		fmt.Printf("From the times the conversion was successful the output was wrong in %d cases (%.2f%%)\n", numNotEqual, errorRate)
 	}
+	if solverCounts != nil {
+		for i, count := range solverCounts {
+			rate := (float64(count) / float64(len(inputs))) * 100.0
+			fmt.Printf("Solver %d in the hybrid solver succeeded on %d of %d tests (%.2f%%)\n", i+1, count, len(inputs), rate)
+		}
+	}
 	fmt.Println("\nRuntime results:")
 	fmt.Printf("One single conversion took %s on average on all successful runs\n", time.Duration(bestSoFarSucc))
 	fmt.Printf("One single conversion took %s on average on all runs (including failed ones)\n", time.Duration(bestSoFarAll))
+	if *dnfFlag != "" {
+		printDNFReport(os.Stdout, records, failures)
+	}
 }
 
-func parseLPBs(path string) ([]*lpb.LPB, []br.ClauseSet, error) {
-	lpbs := make([]*lpb.LPB, 0)
-	dnfs := make([]br.ClauseSet, 0)
+func parseLPBs(path string) ([]input, error) {
 	f, openErr := os.Open(path)
 	if openErr != nil {
-		return nil, nil, openErr
+		return nil, openErr
 	}
 	defer f.Close()
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		nextLPB, parseErr := lpb.ParseLPB(scanner.Text())
-
-		if parseErr != nil {
-			return nil, nil, parseErr
-		}
-		lpbs = append(lpbs, nextLPB)
-		dnfs = append(dnfs, nextLPB.ToDNF())
+	var lpbs []*lpb.LPB
+	var parseErr error
+	if strings.HasSuffix(path, ".opb") {
+		lpbs, _, parseErr = lpb.ParseOPB(f)
+	} else {
+		lpbs, parseErr = lpb.ReadLPBs(f)
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	inputs := make([]input, 0, len(lpbs))
+	for _, nextLPB := range lpbs {
+		inputs = append(inputs, input{name: nextLPB.String(), phi: nextLPB.ToDNF(), nbvar: len(nextLPB.Coefficients)})
 	}
-	return lpbs, dnfs, nil
+	return inputs, nil
 }
 
-func runTest(lpbs []*lpb.LPB, dnfs []br.ClauseSet, n int, verify bool, converter lpb.DNFToLPB) (avgSucc, avgAll float64, numFailedConv, numNotEqual int) {
+func runTest(inputs []input, n int, verify bool, converter lpb.DNFToLPB, timeout time.Duration, workers int, report func(res lpb.Result, verified *bool)) (avgSucc, avgAll float64, numFailedConv, numNotEqual, numTimeouts int, solverCounts []int) {
 	avgSucc = 0.0
 	avgAll = 0.0
 	tSucc := 0
 	tAll := 0
+	hybrid, isHybrid := converter.(*lpb.HybridSolver)
 	for num := 0; num < n; num++ {
 		numFailedConv = 0
 		numNotEqual = 0
-		for i, phi := range dnfs {
-			start := time.Now()
-			// start the solver
-			computedLPB, convErr := converter.Convert(phi, len(lpbs[i].Coefficients))
-			dur := time.Since(start)
+		numTimeouts = 0
+		if isHybrid {
+			solverCounts = make([]int, len(hybrid.Solvers))
+		}
+		jobs := make(chan lpb.Job)
+		go func() {
+			for _, in := range inputs {
+				jobs <- lpb.Job{Phi: in.phi, Nbvar: in.nbvar, Timeout: timeout}
+			}
+			close(jobs)
+		}()
+		for res := range lpb.ConvertAll(context.Background(), converter, jobs, workers, false) {
 			ok := true
-			if convErr != nil {
+			if res.Err != nil {
 				ok = false
 				numFailedConv++
+				if res.Err == context.DeadlineExceeded {
+					numTimeouts++
+				}
+			} else if isHybrid {
+				solverCounts[res.Solver]++
 			}
-			if verify && convErr == nil {
-				if !computedLPB.ToDNF().DeepSortedEquals(phi) {
+			var verified *bool
+			if verify && res.Err == nil {
+				correct := lpb.Verify(res.LPB, res.Job.Phi, res.Job.Nbvar) == nil
+				verified = &correct
+				if !correct {
 					ok = false
 					numNotEqual++
 				}
 			}
+			if cert, isCert := res.Err.(*lpb.NotThresholdError); verify && isCert {
+				correct := cert.Verify(res.Job.Phi, res.Job.Nbvar)
+				verified = &correct
+			}
+			report(res, verified)
 			if ok {
-				avgSucc = iterativeAverage(tSucc, float64(dur), avgSucc)
+				avgSucc = iterativeAverage(tSucc, float64(res.Duration), avgSucc)
 				tSucc++
 			}
 			// always update avgAll
-			avgAll = iterativeAverage(tAll, float64(dur), avgAll)
+			avgAll = iterativeAverage(tAll, float64(res.Duration), avgAll)
 			tAll++
 		}
 	}
@@ -0,0 +1,109 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+// input is a single DNF that gets converted.
+// name describes where the DNF comes from, for LPB files it is the LPB, for
+// DIMACS files the path of the file.
+type input struct {
+	name  string
+	phi   br.ClauseSet
+	nbvar int
+}
+
+// parseFailure describes a DIMACS file that could not be parsed.
+type parseFailure struct {
+	path string
+	err  error
+}
+
+// dnfFiles returns all files described by path: If path is a directory all
+// regular files in the directory (not recursively), if path is a file only
+// the file, otherwise path is used as a glob pattern (see filepath.Glob).
+func dnfFiles(path string) ([]string, error) {
+	info, statErr := os.Stat(path)
+	if statErr == nil && !info.IsDir() {
+		return []string{path}, nil
+	}
+	var candidates []string
+	if statErr == nil {
+		entries, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			candidates = append(candidates, filepath.Join(path, entry.Name()))
+		}
+	} else {
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, statErr
+		}
+		candidates = matches
+	}
+	var res []string
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			res = append(res, candidate)
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("No files found in %s", path)
+	}
+	return res, nil
+}
+
+// parseDNFs parses all DIMACS files described by path (see dnfFiles) with
+// ParsePositiveDIMACS. The DNFs are minimized because the solvers require
+// minimal DNFs. Files that can't be parsed are returned as parse failures.
+func parseDNFs(path string) ([]input, []parseFailure, error) {
+	files, err := dnfFiles(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var inputs []input
+	var failures []parseFailure
+	for _, file := range files {
+		phi, nbvar, parseErr := parseDNFFile(file)
+		if parseErr != nil {
+			failures = append(failures, parseFailure{file, parseErr})
+			continue
+		}
+		inputs = append(inputs, input{name: file, phi: phi.Minimize(), nbvar: nbvar})
+	}
+	return inputs, failures, nil
+}
+
+func parseDNFFile(path string) (br.ClauseSet, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, -1, err
+	}
+	defer f.Close()
+	_, nbvar, phi, err := br.ParsePositiveDIMACS(f)
+	return phi, nbvar, err
+}
@@ -0,0 +1,205 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+// Status values of a record.
+const (
+	statusThreshold    = "threshold"     // an LPB was computed
+	statusNotThreshold = "not threshold" // the solver returned a *NotThresholdError
+	statusError        = "error"         // the solver failed with another error
+)
+
+// record is the result of the conversions of a single input, used for the
+// json and csv output.
+//
+// Duration is the best (smallest) time of all conversions of the input,
+// Success, Status, LPB and Error are the results of the last conversion.
+// Verified is nil if the result was not verified, otherwise it says if the
+// LPB or the certificate of a *NotThresholdError is correct.
+type record struct {
+	Index    int           `json:"index"`
+	Name     string        `json:"name"`
+	Nbvar    int           `json:"nbvar"`
+	Clauses  int           `json:"clauses"`
+	Solver   string        `json:"solver"`
+	Success  bool          `json:"success"`
+	Status   string        `json:"status"`
+	Verified *bool         `json:"verified"`
+	Duration time.Duration `json:"duration_ns"`
+	LPB      string        `json:"lpb"`
+	Error    string        `json:"error,omitempty"`
+	// converted is true once the first result was added
+	converted bool
+}
+
+// update sets the values from the result of a conversion.
+// solverNames are the names of the solvers in a hybrid solver, nil if the
+// solver is not a hybrid solver.
+func (r *record) update(res lpb.Result, solver string, solverNames []string, verified *bool) {
+	if !r.converted || res.Duration < r.Duration {
+		r.Duration = res.Duration
+	}
+	r.converted = true
+	r.Solver = solver
+	if solverNames != nil && res.Solver >= 0 {
+		r.Solver += ":" + solverNames[res.Solver]
+	}
+	r.Success = res.Err == nil
+	r.LPB, r.Error = "", ""
+	switch res.Err.(type) {
+	case nil:
+		r.Status = statusThreshold
+		r.LPB = res.LPB.String()
+	case *lpb.NotThresholdError:
+		r.Status = statusNotThreshold
+		r.Error = res.Err.Error()
+	default:
+		r.Status = statusError
+		r.Error = res.Err.Error()
+	}
+	if verified != nil {
+		r.Verified = verified
+	}
+}
+
+// summary contains the aggregated values of all records.
+// Succeeded is the number of LPBs computed, Rejected the number of DNFs
+// that are not threshold functions and Wrong the number of wrong LPBs and
+// certificates (only if verified).
+type summary struct {
+	Total     int           `json:"total"`
+	Succeeded int           `json:"succeeded"`
+	Rejected  int           `json:"rejected"`
+	Wrong     int           `json:"wrong"`
+	P50       time.Duration `json:"p50_ns"`
+	P95       time.Duration `json:"p95_ns"`
+	Max       time.Duration `json:"max_ns"`
+}
+
+func (s summary) String() string {
+	return fmt.Sprintf("total %d, succeeded %d, rejected %d, wrong %d, p50 %s, p95 %s, max %s",
+		s.Total, s.Succeeded, s.Rejected, s.Wrong, s.P50, s.P95, s.Max)
+}
+
+// summarize computes the summary of the records, the percentiles are computed
+// with the nearest rank method on the durations of all records.
+func summarize(records []*record) summary {
+	res := summary{Total: len(records)}
+	durations := make([]time.Duration, len(records))
+	for i, r := range records {
+		durations[i] = r.Duration
+		switch r.Status {
+		case statusThreshold:
+			res.Succeeded++
+		case statusNotThreshold:
+			res.Rejected++
+		}
+		if r.Verified != nil && !*r.Verified {
+			res.Wrong++
+		}
+	}
+	if len(durations) == 0 {
+		return res
+	}
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+	percentile := func(p int) time.Duration {
+		rank := (p*len(durations) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		return durations[rank-1]
+	}
+	res.P50, res.P95, res.Max = percentile(50), percentile(95), durations[len(durations)-1]
+	return res
+}
+
+// writeJSON writes the records and the summary as a single JSON object.
+func writeJSON(w io.Writer, records []*record) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Records []*record `json:"records"`
+		Summary summary   `json:"summary"`
+	}{records, summarize(records)})
+}
+
+var csvHeader = []string{"index", "name", "nbvar", "clauses", "solver", "success", "status", "verified", "duration_ns", "lpb", "error"}
+
+// writeCSV writes a header and one line for each record. The summary is not
+// part of the CSV, see summary.String.
+func writeCSV(w io.Writer, records []*record) error {
+	writer := csv.NewWriter(w)
+	writer.Write(csvHeader)
+	for _, r := range records {
+		verified := ""
+		if r.Verified != nil {
+			verified = strconv.FormatBool(*r.Verified)
+		}
+		writer.Write([]string{strconv.Itoa(r.Index), r.Name, strconv.Itoa(r.Nbvar), strconv.Itoa(r.Clauses), r.Solver,
+			strconv.FormatBool(r.Success), r.Status, verified, strconv.FormatInt(int64(r.Duration), 10), r.LPB, r.Error})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// printDNFReport prints for each DNF if it was recognized as a threshold
+// function, rejected as not threshold function or if the solver failed. It
+// also prints all files that could not be parsed.
+func printDNFReport(w io.Writer, records []*record, failures []parseFailure) {
+	groups := []struct {
+		status, title string
+	}{
+		{statusThreshold, "Recognized as threshold function"},
+		{statusNotThreshold, "Rejected as not threshold function"},
+		{statusError, "Conversion failed"},
+	}
+	for _, group := range groups {
+		var lines []string
+		for _, r := range records {
+			if r.Status != group.status {
+				continue
+			}
+			line := fmt.Sprintf("  %s: %s%s", r.Name, r.LPB, r.Error)
+			if r.Verified != nil && !*r.Verified {
+				line += " (verification failed)"
+			}
+			lines = append(lines, line)
+		}
+		fmt.Fprintf(w, "\n%s on %d of %d DNFs:\n", group.title, len(lines), len(records))
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+		}
+	}
+	if len(failures) > 0 {
+		fmt.Fprintf(w, "\nFiles that could not be parsed (%d):\n", len(failures))
+		for _, failure := range failures {
+			fmt.Fprintf(w, "  %s: %v\n", failure.path, failure.err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boolrecognition
+
+import (
+	"context"
+	"math/big"
+)
+
+// CountModels returns the number of true points of the positive DNF ϕ with
+// nbvar variables, i.e. the number of assignments that satisfy ϕ.
+//
+// It uses Shannon expansion, each time expanding on the variable that occurs
+// most often. In the worst case this is exponential in the number of
+// variables (counting models of DNFs is #P-hard). For LPBs there is a
+// pseudo-polynomial algorithm, see CountModels in the lpb package.
+//
+// Variables in ϕ must be in the range 0 ≤ v < nbvar.
+func (phi ClauseSet) CountModels(nbvar int) *big.Int {
+	res, _ := phi.CountModelsContext(context.Background(), nbvar)
+	return res
+}
+
+// CountModelsContext works as CountModels but stops once ctx is done and
+// returns ctx.Err().
+func (phi ClauseSet) CountModelsContext(ctx context.Context, nbvar int) (*big.Int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	counter := modelCounter{ctx: ctx}
+	return counter.count(phi.Minimize(), nbvar)
+}
+
+// ChowParameters returns the Chow parameters of the positive DNF ϕ with
+// nbvar variables: For each variable x(i) the number of true points of ϕ with
+// x(i) = 1.
+//
+// Together with CountModels they uniquely identify a threshold function.
+// See CountModels for the complexity.
+func (phi ClauseSet) ChowParameters(nbvar int) []*big.Int {
+	res, _ := phi.ChowParametersContext(context.Background(), nbvar)
+	return res
+}
+
+// ChowParametersContext works as ChowParameters but stops once ctx is done
+// and returns ctx.Err().
+func (phi ClauseSet) ChowParametersContext(ctx context.Context, nbvar int) ([]*big.Int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	res := make([]*big.Int, nbvar)
+	minimal := phi.Minimize()
+	counter := modelCounter{ctx: ctx}
+	for v := range res {
+		count, err := counter.count(setTrue(minimal, v), nbvar-1)
+		if err != nil {
+			return nil, err
+		}
+		res[v] = count
+	}
+	return res, nil
+}
+
+// modelCounter counts models with Shannon expansion and checks ctx every
+// ctxCheckInterval calls.
+type modelCounter struct {
+	ctx   context.Context
+	calls int
+}
+
+// count counts the true points of ϕ where free is the number of variables
+// that are not assigned yet.
+func (c *modelCounter) count(phi ClauseSet, free int) (*big.Int, error) {
+	c.calls++
+	if c.calls%ctxCheckInterval == 0 {
+		if err := c.ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if len(phi) == 0 {
+		return big.NewInt(0), nil
+	}
+	occurrences := make(map[int]int)
+	next, max := -1, 0
+	for _, clause := range phi {
+		if len(clause) == 0 {
+			return new(big.Int).Lsh(big.NewInt(1), uint(free)), nil
+		}
+		for _, v := range clause {
+			occurrences[v]++
+			if occurrences[v] > max {
+				next, max = v, occurrences[v]
+			}
+		}
+	}
+	res, err := c.count(setTrue(phi, next), free-1)
+	if err != nil {
+		return nil, err
+	}
+	other, err := c.count(setFalse(phi, next), free-1)
+	if err != nil {
+		return nil, err
+	}
+	return res.Add(res, other), nil
+}
+
+// setTrue returns the DNF we get if v is set to true, v is removed from all
+// clauses.
+func setTrue(phi ClauseSet, v int) ClauseSet {
+	res := NewClauseSet(len(phi))
+	for _, clause := range phi {
+		newClause := NewClause(len(clause))
+		for _, other := range clause {
+			if other != v {
+				newClause = append(newClause, other)
+			}
+		}
+		res = append(res, newClause)
+	}
+	return res
+}
+
+// setFalse returns the DNF we get if v is set to false, all clauses that
+// contain v are removed.
+func setFalse(phi ClauseSet, v int) ClauseSet {
+	res := NewClauseSet(len(phi))
+	for _, clause := range phi {
+		contains := false
+		for _, other := range clause {
+			if other == v {
+				contains = true
+				break
+			}
+		}
+		if !contains {
+			res = append(res, clause)
+		}
+	}
+	return res
+}
@@ -0,0 +1,146 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boolrecognition
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DIMACSInfo contains the metadata of a DIMACS file: The problem from the
+// problem line (for example "cnf" or "dnf"), the number of variables and
+// clauses and the comment lines (without the leading c).
+type DIMACSInfo struct {
+	Problem   string
+	NbVar     int
+	NbClauses int
+	Comments  []string
+}
+
+// IsCNF returns true if the problem line was "p cnf".
+func (info *DIMACSInfo) IsCNF() bool {
+	return info.Problem == "cnf"
+}
+
+// IsDNF returns true if the problem line was "p dnf".
+func (info *DIMACSInfo) IsDNF() bool {
+	return info.Problem == "dnf"
+}
+
+// ParseDIMACS parses a clause set in DIMACS format from the reader r.
+//
+// In contrast to ParsePositiveDIMACS the literals are not changed: The
+// variable v is represented by v ≥ 1 and ¬v by -v. Whether the clauses are
+// a CNF or DNF is stored in the problem of the returned info.
+//
+// The problem line must appear before the first clause. It is an error if a
+// literal is not in the range 1 ≤ |l| ≤ nbvar or if the number of clauses
+// is not nbclauses. The last clause doesn't have to end with 0, a single 0
+// is the empty clause. Comment lines (lines starting with c) can appear
+// anywhere, they're returned in the order they appear in the input.
+func ParseDIMACS(r io.Reader) (*DIMACSInfo, ClauseSet, error) {
+	scanner := bufio.NewScanner(r)
+	var info *DIMACSInfo
+	var comments []string
+	var clauses ClauseSet
+	var clause Clause
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case line[0] == 'c':
+			comments = append(comments, strings.TrimPrefix(line[1:], " "))
+			continue
+		case line[0] == 'p':
+			if info != nil {
+				return nil, nil, fmt.Errorf("Line %d: Found a second problem line", lineNum)
+			}
+			fields := strings.Fields(line)
+			if len(fields) != 4 || fields[0] != "p" {
+				return nil, nil, fmt.Errorf("Line %d: Invalid problem line \"%s\", expected \"p <problem> <nbvar> <nbclauses>\"", lineNum, line)
+			}
+			nbvar, varErr := strconv.Atoi(fields[2])
+			nbclauses, clausesErr := strconv.Atoi(fields[3])
+			if varErr != nil || clausesErr != nil || nbvar < 0 || nbclauses < 0 {
+				return nil, nil, fmt.Errorf("Line %d: Invalid problem line \"%s\", nbvar and nbclauses must be integers ≥ 0", lineNum, line)
+			}
+			info = &DIMACSInfo{Problem: fields[1], NbVar: nbvar, NbClauses: nbclauses}
+			clauses = NewClauseSet(nbclauses)
+			continue
+		}
+		if info == nil {
+			return nil, nil, fmt.Errorf("Line %d: Found a clause before the problem line", lineNum)
+		}
+		for _, field := range strings.Fields(line) {
+			literal, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Line %d: Invalid literal \"%s\"", lineNum, field)
+			}
+			if literal == 0 {
+				if clause == nil {
+					clause = NewClause(0)
+				}
+				clauses = append(clauses, clause)
+				clause = nil
+				continue
+			}
+			if literal > info.NbVar || -literal > info.NbVar {
+				return nil, nil, fmt.Errorf("Line %d: nbvar was set to %d, but found literal %d", lineNum, info.NbVar, literal)
+			}
+			clause = append(clause, literal)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if info == nil {
+		return nil, nil, fmt.Errorf("No problem line found")
+	}
+	if clause != nil {
+		clauses = append(clauses, clause)
+	}
+	if len(clauses) != info.NbClauses {
+		return nil, nil, fmt.Errorf("nbclauses was set to %d, but found %d clauses", info.NbClauses, len(clauses))
+	}
+	info.Comments = comments
+	return info, clauses, nil
+}
+
+// WriteDIMACS writes the clause set in DIMACS format to the writer, it can
+// be parsed again with ParseDIMACS.
+//
+// The problem line contains the problem and number of variables of the info
+// and the number of clauses in ϕ, the comments are written before the
+// problem line. The literals are written as they are, so they must be in the
+// range 1 ≤ |l| ≤ nbvar. For positive clause sets with variables starting
+// with 0 see ClauseSet.WriteDIMACS and ClauseSet.WriteCNFDIMACS.
+func (info *DIMACSInfo) WriteDIMACS(w io.Writer, phi ClauseSet) error {
+	buffer := bufio.NewWriter(w)
+	for _, comment := range info.Comments {
+		if _, err := fmt.Fprintln(buffer, "c", comment); err != nil {
+			return err
+		}
+	}
+	if err := phi.writeDIMACS(buffer, info.Problem, info.NbVar, false); err != nil {
+		return err
+	}
+	return buffer.Flush()
+}
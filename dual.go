@@ -0,0 +1,101 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boolrecognition
+
+import (
+	"context"
+	"fmt"
+)
+
+// Dualize computes the dual of a positive DNF ϕ.
+//
+// The clauses of the result are the minimal transversals of the clauses in ϕ,
+// i.e. the minimal sets of variables that intersect each clause of ϕ.
+// This result can be read in two ways:
+// As a CNF it is equivalent to ϕ (the prime CNF of ϕ), as a DNF it is the
+// prime DNF of the dual function ϕ^d(x) = ¬ϕ(¬x).
+// Since dualization is an involution calling Dualize on the result (as a
+// positive DNF) gives the minimal DNF of ϕ again.
+//
+// There is also a connection to the maximal false points of ϕ: A point is a
+// maximal false point of ϕ iff the variables that are false in the point are
+// a clause in the dual.
+//
+// It uses Berge multiplication: The transversals are computed incrementally
+// by adding one clause after another, each transversal that does not
+// intersect the new clause gets extended by each variable of the clause and
+// the result is minimized. The clauses in the result are ordered as described
+// in Minimize.
+//
+// Variables in ϕ must be in the range 0 ≤ v < nbvar.
+// Note that the number of transversals can be exponential in the size of ϕ,
+// see DualizeContext for a version that can be cancelled.
+func (phi ClauseSet) Dualize(nbvar int) (ClauseSet, error) {
+	return phi.DualizeContext(context.Background(), nbvar)
+}
+
+// DualizeContext works as Dualize but stops once ctx is done and returns
+// ctx.Err(). ctx is checked before each clause is added and while the
+// transversals are extended.
+func (phi ClauseSet) DualizeContext(ctx context.Context, nbvar int) (ClauseSet, error) {
+	for _, clause := range phi {
+		for _, v := range clause {
+			if v < 0 || v >= nbvar {
+				return nil, fmt.Errorf("Invalid variable %d in Dualize, must be in the range 0 ≤ v < %d", v, nbvar)
+			}
+		}
+	}
+	// start with the empty transversal (dual of false is true)
+	transversals := ClauseSet{NewClause(0)}
+	contains := make([]bool, nbvar)
+	for _, clause := range phi.Minimize() {
+		for _, v := range clause {
+			contains[v] = true
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		next := NewClauseSet(len(transversals))
+		for i, transversal := range transversals {
+			if i%ctxCheckInterval == ctxCheckInterval-1 {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+			}
+			intersects := false
+			for _, v := range transversal {
+				if contains[v] {
+					intersects = true
+					break
+				}
+			}
+			if intersects {
+				next = append(next, transversal)
+				continue
+			}
+			for _, v := range clause {
+				newTransversal := NewClause(len(transversal) + 1)
+				newTransversal = append(newTransversal, transversal...)
+				newTransversal = append(newTransversal, v)
+				next = append(next, newTransversal)
+			}
+		}
+		transversals = next.Minimize()
+		for _, v := range clause {
+			contains[v] = false
+		}
+	}
+	return transversals, nil
+}
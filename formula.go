@@ -107,6 +107,7 @@ func (phi ClauseSet) SortAll() {
 			wg.Done()
 		}(clause)
 	}
+	wg.Wait()
 }
 
 // SortedEquals is a simple equality check for clause sets.
@@ -185,6 +186,7 @@ func (h *positiveDimacsParser) ProblemLine(problem string, nbvar, nbclauses int)
 	h.clauses = NewClauseSet(nbclauses)
 	h.problem = problem
 	h.nbvar = nbvar
+	h.nbclauses = nbclauses
 	return nil
 }
 
@@ -212,6 +214,9 @@ func (h *positiveDimacsParser) NewVariable(value int) error {
 }
 
 func (h *positiveDimacsParser) Done() error {
+	if len(h.clauses) != h.nbclauses {
+		return fmt.Errorf("nbclauses was set to %d, but found %d clauses", h.nbclauses, len(h.clauses))
+	}
 	return nil
 }
 
@@ -225,6 +230,9 @@ func (h *positiveDimacsParser) Done() error {
 // Variables are represented starting with 0, i.e. if you have the clause
 // "c 1 4 7" in your DIMACS file this clause will be represented as {0, 3, 6}.
 //
+// It is an error if the number of clauses is not nbclauses. To parse DIMACS
+// files with negative literals see ParseDIMACS.
+//
 // For more information on the DIMACS format see http://www.satcompetition.org/2009/format-benchmarks2009.html
 func ParsePositiveDIMACS(r io.Reader) (string, int, ClauseSet, error) {
 	h := &positiveDimacsParser{}
@@ -238,10 +246,27 @@ func ParsePositiveDIMACS(r io.Reader) (string, int, ClauseSet, error) {
 //
 // nbvar must be the number of variables in the DNF. If zeroBased is true
 // we add 1 to each variable before writing (in DIMACS variables always
-// start with 1).
+// start with 1), this works only for positive DNFs. If zeroBased is false the
+// literals are written as they are, so signed literals (-v for ¬v) are
+// written correctly. To write comments or other problems see
+// DIMACSInfo.WriteDIMACS.
 func (phi ClauseSet) WriteDIMACS(w io.Writer, nbvar int, zeroBased bool) error {
+	return phi.writeDIMACS(w, "dnf", nbvar, zeroBased)
+}
+
+// WriteCNFDIMACS writes the clause set as a CNF in DIMACS format to the
+// writer, i.e. the problem line is "p cnf". See WriteDIMACS for details.
+//
+// For example the CNF of a positive DNF can be computed with Dualize.
+func (phi ClauseSet) WriteCNFDIMACS(w io.Writer, nbvar int, zeroBased bool) error {
+	return phi.writeDIMACS(w, "cnf", nbvar, zeroBased)
+}
+
+// writeDIMACS writes the clause set in DIMACS format with the given problem
+// in the problem line.
+func (phi ClauseSet) writeDIMACS(w io.Writer, problem string, nbvar int, zeroBased bool) error {
 	buffer := bufio.NewWriter(w)
-	if _, err := fmt.Fprintln(buffer, "p dnf", nbvar, len(phi)); err != nil {
+	if _, err := fmt.Fprintln(buffer, "p", problem, nbvar, len(phi)); err != nil {
 		return err
 	}
 	for _, clause := range phi {
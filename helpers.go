@@ -43,3 +43,7 @@ func BinSearch(s []int, val int) int {
 	}
 	return -1
 }
+
+// ctxCheckInterval is the number of iterations after which long running loops
+// check if their context is done.
+const ctxCheckInterval = 1024
@@ -0,0 +1,236 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lpb
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+// ClonableDNFToLPB is a DNFToLPB that can create copies of itself.
+//
+// Clone must return a solver that can be used concurrently with the original
+// solver (and all other copies), or nil if that's not possible.
+// ConvertAll uses this to give each worker its own solver.
+//
+// For CombinatorialSolver, LPSolver and ChowSolver Clone returns a shallow
+// copy. This is fine because these solvers only store options that are never
+// changed by Convert, see the documentation of the types.
+// The copy of a CombinatorialSolver shares the TreeSolver (and thus the
+// ColumnHandler of a SimpleTreeSolver) with the original solver, so
+// implementations of TreeSolver and ColumnHandler must be stateless or safe
+// for concurrent use. SimpleTreeSolver and MinColumnHandler are both stateless.
+type ClonableDNFToLPB interface {
+	DNFToLPB
+	Clone() DNFToLPB
+}
+
+// Clone returns a shallow copy of the solver, TSolver is shared with the copy.
+// So TSolver must be safe for concurrent use, see ClonableDNFToLPB.
+func (s *CombinatorialSolver) Clone() DNFToLPB {
+	res := *s
+	return &res
+}
+
+// Clone returns a shallow copy of the solver, Backend is shared with the copy.
+func (s *LPSolver) Clone() DNFToLPB {
+	res := *s
+	return &res
+}
+
+// Clone returns a shallow copy of the solver.
+func (s *ChowSolver) Clone() DNFToLPB {
+	res := *s
+	return &res
+}
+
+// Clone returns a hybrid solver with a copy of each solver in the chain.
+// It returns nil if one of the solvers can't be cloned.
+func (s *HybridSolver) Clone() DNFToLPB {
+	solvers := make([]DNFToLPB, len(s.Solvers))
+	for i, solver := range s.Solvers {
+		if solvers[i] = cloneSolver(solver); solvers[i] == nil {
+			return nil
+		}
+	}
+	return &HybridSolver{Solvers: solvers}
+}
+
+// cloneSolver returns a copy of the solver or nil if it does not implement
+// ClonableDNFToLPB or can't be cloned.
+func cloneSolver(solver DNFToLPB) DNFToLPB {
+	clonable, ok := solver.(ClonableDNFToLPB)
+	if !ok {
+		return nil
+	}
+	return clonable.Clone()
+}
+
+// Job is a DNF that should be converted by ConvertAll.
+//
+// If Timeout > 0 the conversion is cancelled after Timeout, the result then
+// contains the error context.DeadlineExceeded.
+type Job struct {
+	Phi     br.ClauseSet
+	Nbvar   int
+	Timeout time.Duration
+}
+
+// Result is the result of a Job in ConvertAll.
+//
+// Index is the position of the job in the input channel (starting with 0),
+// LPB and Err are the results of ConvertContext and Duration is the time the
+// conversion took.
+// If the solver is a *HybridSolver Solver is the index of the solver that
+// computed the LPB (see HybridSolver.ConvertReport), otherwise it is -1.
+type Result struct {
+	Job      Job
+	Index    int
+	LPB      *LPB
+	Err      error
+	Duration time.Duration
+	Solver   int
+}
+
+// ConvertAll converts all jobs from inputs with a pool of workers and writes
+// the results to the returned channel.
+//
+// If ordered is false the results are written as soon as they're available.
+// Otherwise they're written in the order of the jobs in inputs: A result is
+// only written once the results of all previous jobs are written. To bound
+// the memory a job is only started if it is less than workers jobs behind the
+// next result that must be written, so at most workers results are stored.
+//
+// If workers ≤ 0 runtime.NumCPU() workers are used. Solvers (and the LP
+// backends they use) are not required to be safe for concurrent use, so each
+// worker gets its own copy of solver created with Clone, see
+// ClonableDNFToLPB. If solver can't be cloned only one worker is used that
+// works directly on solver.
+//
+// Each job from inputs yields exactly one result. If the solver panics on a
+// job the result contains an error that describes the panic. Once ctx is done
+// the remaining jobs are not converted, their result contains ctx.Err(). The
+// result channel gets closed once inputs is closed and all results were
+// written, so inputs must be closed and all results must be read.
+func ConvertAll(ctx context.Context, solver DNFToLPB, inputs <-chan Job, workers int, ordered bool) <-chan Result {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	solvers := make([]DNFToLPB, workers)
+	for i := range solvers {
+		if solvers[i] = cloneSolver(solver); solvers[i] == nil {
+			solvers = []DNFToLPB{solver}
+			break
+		}
+	}
+	type indexedJob struct {
+		job   Job
+		index int
+	}
+	jobs := make(chan indexedJob)
+	results := make(chan Result, len(solvers))
+	// if ordered is true a job must get a slot before it is started, the slot
+	// is released once its result was written
+	var slots chan struct{}
+	if ordered {
+		slots = make(chan struct{}, len(solvers))
+	}
+	// assign the indexes
+	go func() {
+		index := 0
+		for job := range inputs {
+			if ordered {
+				slots <- struct{}{}
+			}
+			jobs <- indexedJob{job, index}
+			index++
+		}
+		close(jobs)
+	}()
+	var wg sync.WaitGroup
+	wg.Add(len(solvers))
+	for _, workerSolver := range solvers {
+		go func(workerSolver DNFToLPB) {
+			defer wg.Done()
+			for next := range jobs {
+				results <- convertJob(ctx, workerSolver, next.job, next.index)
+			}
+		}(workerSolver)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	if !ordered {
+		return results
+	}
+	res := make(chan Result)
+	go func() {
+		// the jobs that are not written yet have the indexes
+		// next, ..., next + len(solvers) - 1, so each has its own position in
+		// pending
+		pending := make([]*Result, len(solvers))
+		next := 0
+		for result := range results {
+			result := result
+			pending[result.Index%len(pending)] = &result
+			for pending[next%len(pending)] != nil {
+				res <- *pending[next%len(pending)]
+				pending[next%len(pending)] = nil
+				next++
+				<-slots
+			}
+		}
+		close(res)
+	}()
+	return res
+}
+
+// convertJob converts a single job in ConvertAll.
+//
+// If the solver panics the panic is recovered and returned as error in the
+// result, so a single job can't stop the whole batch.
+func convertJob(ctx context.Context, solver DNFToLPB, job Job, index int) (res Result) {
+	res = Result{Job: job, Index: index, Solver: -1}
+	if err := ctx.Err(); err != nil {
+		res.Err = err
+		return res
+	}
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			res.LPB, res.Solver = nil, -1
+			res.Err = fmt.Errorf("Solver panicked: %v", r)
+			res.Duration = time.Since(start)
+		}
+	}()
+	if hybrid, isHybrid := solver.(*HybridSolver); isHybrid {
+		res.LPB, res.Solver, res.Err = hybrid.ConvertReportContext(ctx, job.Phi, job.Nbvar)
+	} else {
+		res.LPB, res.Err = solver.ConvertContext(ctx, job.Phi, job.Nbvar)
+	}
+	res.Duration = time.Since(start)
+	return res
+}
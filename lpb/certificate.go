@@ -0,0 +1,428 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lpb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+// maxWitnessPoints is the maximal number of true points in an
+// AsummabilityWitness computed from a Farkas certificate. If the multipliers
+// are too big we don't create a witness.
+const maxWitnessPoints = 10000
+
+// RegularityCertificate describes a violation of the regularity of a DNF, see
+// DNFTree.RegularityViolation.
+//
+// MTP is a minimal true point with MTP[First] = 0 and MTP[Second] = 1.
+// The point we get by swapping the values of First and Second is a false
+// point, thus First is not at least as important as Second.
+type RegularityCertificate struct {
+	MTP           br.BooleanVector
+	First, Second int
+}
+
+// Swapped returns the point we get by swapping First and Second in MTP.
+func (c *RegularityCertificate) Swapped() br.BooleanVector {
+	res := c.MTP.Clone()
+	res[c.First], res[c.Second] = res[c.Second], res[c.First]
+	return res
+}
+
+// Verify checks if the certificate is correct for ϕ, that is MTP is a true
+// point, MTP[First] = 0, MTP[Second] = 1 and the swapped point is a false
+// point.
+func (c *RegularityCertificate) Verify(phi br.ClauseSet, nbvar int) bool {
+	if len(c.MTP) != nbvar || c.First < 0 || c.First >= nbvar || c.Second < 0 || c.Second >= nbvar {
+		return false
+	}
+	if c.MTP[c.First] || !c.MTP[c.Second] {
+		return false
+	}
+	return phi.EvalDNF(c.MTP) && !phi.EvalDNF(c.Swapped())
+}
+
+// Rename renames the variables in the certificate, see LPB.Rename.
+func (c *RegularityCertificate) Rename(renaming []int) *RegularityCertificate {
+	if renaming == nil {
+		return c
+	}
+	return &RegularityCertificate{MTP: renamePoint(c.MTP, renaming),
+		First:  renaming[c.First],
+		Second: renaming[c.Second],
+	}
+}
+
+// AsummabilityWitness proves that a function is not a threshold function.
+//
+// It contains the same number of true and false points and the sum of all true
+// points is equal to the sum of all false points (in each component).
+// Points may occur more than once.
+// If there were weights w and a threshold d the weighted sum of each true
+// point is ≥ d and the weighted sum of each false point is < d. So the
+// weighted sum of all true points is greater than the weighted sum of all
+// false points, but this is not possible because they're equal.
+type AsummabilityWitness struct {
+	TruePoints, FalsePoints []br.BooleanVector
+}
+
+// Verify checks if the witness is correct for ϕ.
+func (w *AsummabilityWitness) Verify(phi br.ClauseSet, nbvar int) bool {
+	if len(w.TruePoints) == 0 || len(w.TruePoints) != len(w.FalsePoints) {
+		return false
+	}
+	sums := make([]int, nbvar)
+	for _, point := range w.TruePoints {
+		if len(point) != nbvar || !phi.EvalDNF(point) {
+			return false
+		}
+		for i, val := range point {
+			if val {
+				sums[i]++
+			}
+		}
+	}
+	for _, point := range w.FalsePoints {
+		if len(point) != nbvar || phi.EvalDNF(point) {
+			return false
+		}
+		for i, val := range point {
+			if val {
+				sums[i]--
+			}
+		}
+	}
+	for _, sum := range sums {
+		if sum != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Rename renames the variables in the witness, see LPB.Rename.
+func (w *AsummabilityWitness) Rename(renaming []int) *AsummabilityWitness {
+	if renaming == nil {
+		return w
+	}
+	res := &AsummabilityWitness{TruePoints: make([]br.BooleanVector, len(w.TruePoints)),
+		FalsePoints: make([]br.BooleanVector, len(w.FalsePoints)),
+	}
+	for i, point := range w.TruePoints {
+		res.TruePoints[i] = renamePoint(point, renaming)
+	}
+	for i, point := range w.FalsePoints {
+		res.FalsePoints[i] = renamePoint(point, renaming)
+	}
+	return res
+}
+
+// NotThresholdError is returned by a DNFToLPB converter if the DNF is not
+// a threshold function. It contains a certificate that can be checked with
+// Verify.
+//
+// Regularity is set if the regularity test failed. Asummability is set if we
+// found a witness that the function is not a threshold function, this is
+// usually the case. Note that a RegularityCertificate alone does not prove
+// that the function is not a threshold function if the variables were not
+// sorted according to the Winder matrix.
+type NotThresholdError struct {
+	Regularity   *RegularityCertificate
+	Asummability *AsummabilityWitness
+}
+
+func (err *NotThresholdError) Error() string {
+	buffer := new(bytes.Buffer)
+	buffer.WriteString("DNF is not a threshold function")
+	if err.Regularity != nil {
+		fmt.Fprintf(buffer, ": DNF is not regular, minimal true point %s is true but %s is false",
+			err.Regularity.MTP, err.Regularity.Swapped())
+	}
+	if err.Asummability != nil {
+		fmt.Fprintf(buffer, ", found %d-asummability witness", len(err.Asummability.TruePoints))
+	}
+	return buffer.String()
+}
+
+// Verify checks if all certificates in the error are correct for ϕ.
+// At least one certificate must be set.
+func (err *NotThresholdError) Verify(phi br.ClauseSet, nbvar int) bool {
+	if err.Regularity == nil && err.Asummability == nil {
+		return false
+	}
+	if err.Regularity != nil && !err.Regularity.Verify(phi, nbvar) {
+		return false
+	}
+	if err.Asummability != nil && !err.Asummability.Verify(phi, nbvar) {
+		return false
+	}
+	return true
+}
+
+// Rename renames the variables in all certificates, see LPB.Rename.
+func (err *NotThresholdError) Rename(renaming []int) *NotThresholdError {
+	res := &NotThresholdError{}
+	if err.Regularity != nil {
+		res.Regularity = err.Regularity.Rename(renaming)
+	}
+	if err.Asummability != nil {
+		res.Asummability = err.Asummability.Rename(renaming)
+	}
+	return res
+}
+
+// FindNotThresholdCertificate tests if ϕ is a threshold function and returns
+// a certificate if it is not. If ϕ is a threshold function it returns nil.
+//
+// ϕ must be a positive and minimal DNF. It first checks if ϕ is regular (with
+// the variables sorted according to the Winder matrix), if it's not regular
+// the certificate contains the violation of the regularity and a witness
+// with two true and two false points. Otherwise we solve the linear program
+// without integer constraints and if it is infeasible use the Farkas
+// certificate of the lp to build the witness.
+//
+// Once ctx is done it returns ctx.Err(), the deadline of ctx is used as
+// timeout for the linear program.
+func FindNotThresholdCertificate(ctx context.Context, phi br.ClauseSet, nbvar int) (*NotThresholdError, error) {
+	lp := NewLinearProgram(phi, nbvar, true, true)
+	cert, err := lp.findCertificate(ctx)
+	if cert == nil || err != nil {
+		return nil, err
+	}
+	return cert.Rename(lp.ReverseRenaming), nil
+}
+
+// findCertificate is FindNotThresholdCertificate in the ids of the linear
+// program, the variables must be sorted according to the Winder matrix.
+func (lp *LinearProgram) findCertificate(ctx context.Context) (*NotThresholdError, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if isFinal(lp.Phi) != NotFinal {
+		return nil, nil
+	}
+	mtps := ComputeMTPs(lp.Phi, lp.Nbvar)
+	if err := lp.Tree.BuildTreeContext(ctx); err != nil {
+		return nil, err
+	}
+	reg, err := lp.Tree.RegularityViolation(ctx, mtps)
+	if err != nil {
+		return nil, err
+	}
+	if reg != nil {
+		return &NotThresholdError{Regularity: reg,
+			Asummability: swapWitness(lp.Phi, mtps, reg)}, nil
+	}
+	mfps := ComputeMFPs(mtps, true)
+	witness, err := asummabilityWitness(ctx, mtps, mfps, lp.Nbvar)
+	if witness == nil || err != nil {
+		return nil, err
+	}
+	return &NotThresholdError{Asummability: witness}, nil
+}
+
+// regularityError creates the error if the regularity test failed, reg is
+// the violation found by DNFTree.RegularityViolation.
+// If we can't find a witness for the asummability directly (because the
+// variables are not sorted) we fall back to FindNotThresholdCertificate.
+// Once ctx is done ctx.Err() is returned.
+func (lp *LinearProgram) regularityError(ctx context.Context, mtps []*br.BitVector, reg *RegularityCertificate) error {
+	res := &NotThresholdError{Regularity: reg,
+		Asummability: swapWitness(lp.Phi, mtps, reg)}
+	if res.Asummability == nil {
+		cert, err := FindNotThresholdCertificate(ctx, lp.Phi, lp.Nbvar)
+		if err != nil {
+			return err
+		}
+		if cert != nil {
+			res.Asummability = cert.Asummability
+		}
+	}
+	return res
+}
+
+// infeasibleError creates the error if the lp can't be solved.
+// If the lp is infeasible it returns a NotThresholdError. If the witness can't be created (for example because the lp was set up
+// without a regularity test for a DNF that is not regular) it falls back to
+// FindNotThresholdCertificate. If no certificate can be found err is returned.
+// Once ctx is done ctx.Err() is returned, as well as errors while setting up
+// the lp for the witness.
+func (lp *LinearProgram) infeasibleError(ctx context.Context, err error) error {
+	witness, witnessErr := asummabilityWitness(ctx, lp.MTPs, lp.MFPs, lp.Nbvar)
+	if witnessErr != nil {
+		return witnessErr
+	}
+	if witness != nil && witness.Verify(lp.Phi, lp.Nbvar) {
+		return &NotThresholdError{Asummability: witness}
+	}
+	cert, ctxErr := FindNotThresholdCertificate(ctx, lp.Phi, lp.Nbvar)
+	if ctxErr != nil {
+		return ctxErr
+	}
+	if cert != nil {
+		return cert
+	}
+	return err
+}
+
+// swapWitness tries to find a minimal true point Q with Q[Second] = 0 s.t.
+// adding First to Q and swapping First and Second yields a false point.
+// Together with the regularity violation this gives a witness with two true
+// and two false points.
+// If the variables are sorted according to the Winder matrix such a point
+// always exists, otherwise it returns nil if no point was found.
+func swapWitness(phi br.ClauseSet, mtps []*br.BitVector, reg *RegularityCertificate) *AsummabilityWitness {
+	for _, mtp := range mtps {
+		if mtp.Test(reg.Second) {
+			continue
+		}
+		q := mtp.ToBooleanVector()
+		q[reg.First] = true
+		swapped := q.Clone()
+		swapped[reg.First], swapped[reg.Second] = false, true
+		if !phi.EvalDNF(swapped) {
+			return &AsummabilityWitness{TruePoints: []br.BooleanVector{reg.MTP.Clone(), q},
+				FalsePoints: []br.BooleanVector{reg.Swapped(), swapped},
+			}
+		}
+	}
+	return nil
+}
+
+// asummabilityWitness solves the lp with the constraints for mtps and mfps
+// (without integer constraints) with SimplexLP. If the lp is infeasible it
+// builds a witness from the Farkas certificate, otherwise it returns nil.
+//
+// The multipliers λ for the true points and μ for the false points satisfy
+// ∑ λ(i) ⋅ t(i) ≤ ∑ μ(j) ⋅ f(j) (in each component) and ∑ λ(i) ≥ ∑ μ(j) > 0.
+// We scale them to integers and add each point as often as its multiplier
+// says. Then we remove true points until there are as many true points as
+// false points. Finally we set variables in the true points (so they're still
+// true points) until both sums are equal.
+//
+// The deadline of ctx is used as timeout for the lp, if ctx is done ctx.Err()
+// is returned. If the lp can't be formulated that error is returned.
+func asummabilityWitness(ctx context.Context, mtps, mfps []*br.BitVector, nbvar int) (*AsummabilityWitness, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(mtps) == 0 || len(mfps) == 0 {
+		return nil, nil
+	}
+	program, err := FormulateLP(mtps, mfps, nbvar, nil, TightenNone, ObjectiveNone, NewSimplexLP, false)
+	if err != nil {
+		return nil, err
+	}
+	deadline, hasDeadline := ctx.Deadline()
+	if hasDeadline {
+		program.(TimeoutLPBackend).SetTimeout(time.Until(deadline))
+	}
+	status := program.Solve()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if hasDeadline && !time.Now().Before(deadline) {
+		// the lp stopped because of the deadline but ctx is not done yet
+		return nil, context.DeadlineExceeded
+	}
+	if status != LPInfeasible {
+		return nil, nil
+	}
+	return farkasWitness(program.(FarkasLPBackend).FarkasDual(), mtps, mfps, nbvar), nil
+}
+
+// farkasWitness builds the witness from the Farkas certificate of the lp, see
+// asummabilityWitness. It returns nil if the multipliers are too big.
+func farkasWitness(farkas []*big.Rat, mtps, mfps []*br.BitVector, nbvar int) *AsummabilityWitness {
+	if len(farkas) != len(mtps)+len(mfps) {
+		return nil
+	}
+	// scale all multipliers to integers, the multipliers for the false points
+	// are ≤ 0
+	lcd := big.NewInt(1)
+	gcd := new(big.Int)
+	for _, val := range farkas {
+		denom := val.Denom()
+		gcd.GCD(nil, nil, lcd, denom)
+		lcd.Mul(lcd, new(big.Int).Quo(denom, gcd))
+	}
+	counts := make([]int, len(farkas))
+	total := 0
+	for i, val := range farkas {
+		num := new(big.Int).Mul(val.Num(), new(big.Int).Quo(lcd, val.Denom()))
+		num.Abs(num)
+		if !num.IsInt64() || num.Int64() > maxWitnessPoints {
+			return nil
+		}
+		counts[i] = int(num.Int64())
+		total += counts[i]
+		if total > 2*maxWitnessPoints {
+			return nil
+		}
+	}
+	res := &AsummabilityWitness{}
+	for i, mfp := range mfps {
+		for k := 0; k < counts[len(mtps)+i]; k++ {
+			res.FalsePoints = append(res.FalsePoints, mfp.ToBooleanVector())
+		}
+	}
+	for i, mtp := range mtps {
+		for k := 0; k < counts[i] && len(res.TruePoints) < len(res.FalsePoints); k++ {
+			res.TruePoints = append(res.TruePoints, mtp.ToBooleanVector())
+		}
+	}
+	if len(res.FalsePoints) == 0 || len(res.TruePoints) != len(res.FalsePoints) {
+		return nil
+	}
+	// compute the difference between the false and true points and fill up
+	// the true points
+	for v := 0; v < nbvar; v++ {
+		diff := 0
+		for k := range res.FalsePoints {
+			if res.FalsePoints[k][v] {
+				diff++
+			}
+			if res.TruePoints[k][v] {
+				diff--
+			}
+		}
+		for k := 0; k < len(res.TruePoints) && diff > 0; k++ {
+			if !res.TruePoints[k][v] {
+				res.TruePoints[k][v] = true
+				diff--
+			}
+		}
+		if diff != 0 {
+			return nil
+		}
+	}
+	return res
+}
+
+// renamePoint renames the variables in the point: the value of variable i
+// becomes the value of variable renaming[i].
+func renamePoint(point br.BooleanVector, renaming []int) br.BooleanVector {
+	res := br.NewBooleanVector(len(point))
+	for i, val := range point {
+		res[renaming[i]] = val
+	}
+	return res
+}
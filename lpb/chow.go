@@ -0,0 +1,146 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lpb
+
+import "math/big"
+
+// CountModels returns the number of true points of the LPB, i.e. the number
+// of assignments s.t. a_1 ⋅ x_1 + ... + a_n ⋅ x_n ≥ d.
+//
+// It uses dynamic programming over the sums of the coefficients: For each
+// sum s < d it counts the assignments of the first i variables with sum s,
+// all sums ≥ d are counted together. So the runtime is in O(n ⋅ d)
+// (pseudo-polynomial). Coefficients that are ∞ always reach the threshold.
+func (lpb *LPB) CountModels() *big.Int {
+	return lpb.countTrue(-1, lpb.Threshold)
+}
+
+// ChowParameters returns the Chow parameters of the LPB: For each variable
+// x(i) the number of true points with x(i) = 1.
+//
+// Chow's theorem states that a threshold function is uniquely identified
+// by its Chow parameters and its number of true points (CountModels), see
+// ChowEquals.
+// It uses the same dynamic programming approach as CountModels for each
+// variable, so the runtime is in O(n² ⋅ d).
+func (lpb *LPB) ChowParameters() []*big.Int {
+	n := len(lpb.Coefficients)
+	res := make([]*big.Int, n)
+	for i, coeff := range lpb.Coefficients {
+		switch {
+		case coeff == PositiveInfinity:
+			// x(i) = 1 is always a true point
+			res[i] = pow2(n - 1)
+		case lpb.Threshold == PositiveInfinity || lpb.Threshold == NegativeInfinity:
+			res[i] = lpb.countTrue(i, lpb.Threshold)
+		default:
+			// don't use Sub, a negative result might be interpreted as ∞ or -∞
+			degree := int(lpb.Threshold) - int(coeff)
+			if degree <= 0 {
+				res[i] = pow2(n - 1)
+			} else {
+				res[i] = lpb.countTrue(i, LPBCoeff(degree))
+			}
+		}
+	}
+	return res
+}
+
+// ChowEquals checks if two LPBs represent the same function by comparing
+// their Chow parameters and number of true points.
+//
+// Because LPBs represent threshold functions this is the case iff both LPBs
+// are equivalent (Chow's theorem). The test doesn't compute the DNFs of the
+// LPBs, so it is a fast way to show that two LPBs are not equivalent.
+// If the LPBs don't have the same number of variables false is returned.
+func (lpb *LPB) ChowEquals(other *LPB) bool {
+	if len(lpb.Coefficients) != len(other.Coefficients) {
+		return false
+	}
+	if lpb.CountModels().Cmp(other.CountModels()) != 0 {
+		return false
+	}
+	otherChow := other.ChowParameters()
+	for i, val := range lpb.ChowParameters() {
+		if val.Cmp(otherChow[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// countTrue counts the assignments of all variables except skip (-1 if no
+// variable should be skipped) s.t. the sum of the coefficients is ≥ degree.
+func (lpb *LPB) countTrue(skip int, degree LPBCoeff) *big.Int {
+	n := len(lpb.Coefficients)
+	if skip >= 0 {
+		n--
+	}
+	switch {
+	case degree == NegativeInfinity || degree.Compare(0) <= 0:
+		return pow2(n)
+	case degree == PositiveInfinity:
+		// only assignments with a coefficient ∞ reach the threshold
+		numInf := 0
+		for i, coeff := range lpb.Coefficients {
+			if i != skip && coeff == PositiveInfinity {
+				numInf++
+			}
+		}
+		return new(big.Int).Sub(pow2(n), pow2(n-numInf))
+	}
+	d := int(degree)
+	// counts[s] is the number of assignments with sum s for s < d and the
+	// number of assignments with sum ≥ d for s = d
+	counts := make([]*big.Int, d+1)
+	next := make([]*big.Int, d+1)
+	for s := range counts {
+		counts[s] = new(big.Int)
+		next[s] = new(big.Int)
+	}
+	counts[0].SetInt64(1)
+	for i, coeff := range lpb.Coefficients {
+		if i == skip {
+			continue
+		}
+		c := d
+		if coeff != PositiveInfinity && int(coeff) < d {
+			c = int(coeff)
+		}
+		for s := range next {
+			next[s].SetInt64(0)
+		}
+		for s, count := range counts {
+			if count.Sign() == 0 {
+				continue
+			}
+			// x(i) = 0
+			next[s].Add(next[s], count)
+			// x(i) = 1
+			newSum := s + c
+			if newSum > d {
+				newSum = d
+			}
+			next[newSum].Add(next[newSum], count)
+		}
+		counts, next = next, counts
+	}
+	return counts[d]
+}
+
+// pow2 returns 2^n.
+func pow2(n int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(n))
+}
@@ -0,0 +1,266 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lpb
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+// ErrNotConverged is returned by ChowSolver if no LPB was found after
+// MaxUpdates updates and the DNF might still be a threshold function.
+var ErrNotConverged error = errors.New("Chow solver did not converge.")
+
+// chowScales is the number of scales tried by ChowSolver.
+const chowScales = 8
+
+// ChowSolver implements the DNFToLPB interface by deriving the coefficients
+// from the Chow parameters of the DNF.
+//
+// The Chow parameters are the number of true points c and for each variable
+// x(i) the number of true points c(i) with x(i) = 1. The candidate
+// coefficients are w(i) = 2 ⋅ c(i) - c, the number of true points with
+// x(i) = 1 minus the number of true points with x(i) = 0. These values often
+// represent the function already. If they don't the candidate is scaled down
+// to small integers and refined with a perceptron on the minimal true points
+// (the clauses of the minimized DNF) and maximal false points (computed from
+// the dual of ϕ): Each minimal true point with a sum < d is added to the
+// coefficients, each maximal false point with a sum ≥ d is subtracted.
+// Negative coefficients are set to 0 after each update.
+// The refinement is not guaranteed to find an LPB, even for threshold
+// functions. Usually the problem is that the rounded values are too coarse, so
+// the refinement is restarted a few times with a four times greater scale
+// (starting with 2 ⋅ nbvar for the greatest coefficient).
+// MaxUpdates is the total number of updates for all scales.
+//
+// If no LPB was found it checks if ϕ is a threshold function, if it is not
+// a *NotThresholdError is returned, see FindNotThresholdCertificate.
+// Otherwise ErrNotConverged is returned.
+//
+// The DNF doesn't have to be sorted and the variables are not renamed.
+//
+// The solver only stores options, Convert doesn't change them, so it can be
+// used concurrently. Clone relies on this, if a field is added that Convert
+// changes Clone must copy it.
+type ChowSolver struct {
+	MaxUpdates int
+}
+
+// NewChowSolver returns a new Chow solver with MaxUpdates set to 100000.
+func NewChowSolver() *ChowSolver {
+	return &ChowSolver{MaxUpdates: 100000}
+}
+
+// Convert computes the Chow parameters and refines the candidate LPB as
+// described in the documentation of ChowSolver.
+func (s *ChowSolver) Convert(phi br.ClauseSet, nbvar int) (*LPB, error) {
+	return s.ConvertContext(context.Background(), phi, nbvar)
+}
+
+// ConvertContext works as Convert but stops once ctx is done, in this case it
+// returns ctx.Err(). ctx is checked while the dual and the Chow parameters
+// are computed, in each round of the refinement and passed to
+// FindNotThresholdCertificate.
+func (s *ChowSolver) ConvertContext(ctx context.Context, phi br.ClauseSet, nbvar int) (*LPB, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	minimal := phi.Minimize()
+	switch isFinal(minimal) {
+	case IsFalse:
+		return NewLPB(1, make([]LPBCoeff, nbvar)), nil
+	case IsTrue:
+		return NewLPB(0, make([]LPBCoeff, nbvar)), nil
+	}
+	dual, err := minimal.DualizeContext(ctx, nbvar)
+	if err != nil {
+		return nil, err
+	}
+	// the maximal false points, each represented by the variables that are
+	// true
+	mfps := br.NewClauseSet(len(dual))
+	inDual := make([]bool, nbvar)
+	for _, clause := range dual {
+		for _, v := range clause {
+			inDual[v] = true
+		}
+		point := br.NewClause(nbvar - len(clause))
+		for v := 0; v < nbvar; v++ {
+			if !inDual[v] {
+				point = append(point, v)
+			}
+		}
+		mfps = append(mfps, point)
+		for _, v := range clause {
+			inDual[v] = false
+		}
+	}
+	weights, err := chowWeights(ctx, minimal, nbvar)
+	if err != nil {
+		return nil, err
+	}
+	if weights != nil {
+		if res := separate(weights, minimal, mfps); res != nil {
+			return res, nil
+		}
+	}
+	scale := int64(2 * nbvar)
+	for i := 0; i < chowScales; i++ {
+		if res := perceptron(ctx, scaleWeights(weights, nbvar, scale), minimal, mfps, s.MaxUpdates/chowScales); res != nil {
+			return res, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		scale *= 4
+	}
+	cert, err := FindNotThresholdCertificate(ctx, minimal, nbvar)
+	if err != nil {
+		return nil, err
+	}
+	if cert != nil {
+		return nil, cert
+	}
+	return nil, ErrNotConverged
+}
+
+// chowWeights computes the candidate weights 2 ⋅ c(i) - c. It returns nil if
+// the values are too big. Once ctx is done ctx.Err() is returned.
+func chowWeights(ctx context.Context, phi br.ClauseSet, nbvar int) ([]int64, error) {
+	count, err := phi.CountModelsContext(ctx, nbvar)
+	if err != nil {
+		return nil, err
+	}
+	chow, err := phi.ChowParametersContext(ctx, nbvar)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]int64, nbvar)
+	for i, c := range chow {
+		w := new(big.Int).Lsh(c, 1)
+		w.Sub(w, count)
+		if w.BitLen() > 31 {
+			return nil, nil
+		}
+		res[i] = w.Int64()
+	}
+	return res, nil
+}
+
+// scaleWeights scales and rounds the weights s.t. the greatest weight is scale.
+// If weights is nil all weights are set to 1.
+func scaleWeights(weights []int64, nbvar int, scale int64) []int64 {
+	res := make([]int64, nbvar)
+	var max int64
+	for _, w := range weights {
+		if w > max {
+			max = w
+		}
+	}
+	if max == 0 {
+		for i := range res {
+			res[i] = 1
+		}
+		return res
+	}
+	for i, w := range weights {
+		res[i] = int64(math.Floor(float64(w)*float64(scale)/float64(max) + 0.5))
+	}
+	return res
+}
+
+// separate returns the LPB with the given coefficients and the smallest sum
+// of a minimal true point as threshold if all maximal false points have a
+// smaller sum. Otherwise it returns nil.
+func separate(weights []int64, mtps, mfps br.ClauseSet) *LPB {
+	threshold := int64(-1)
+	for _, point := range mtps {
+		if sum := pointSum(weights, point); threshold < 0 || sum < threshold {
+			threshold = sum
+		}
+	}
+	if threshold <= 0 {
+		return nil
+	}
+	for _, point := range mfps {
+		if pointSum(weights, point) >= threshold {
+			return nil
+		}
+	}
+	coeffs := make([]LPBCoeff, len(weights))
+	for i, w := range weights {
+		coeffs[i] = LPBCoeff(w)
+	}
+	return NewLPB(LPBCoeff(threshold), coeffs)
+}
+
+// perceptron refines the weights until they separate the minimal true points
+// from the maximal false points, see ChowSolver. It returns nil if there was
+// no result after maxUpdates updates or if ctx is done.
+func perceptron(ctx context.Context, weights []int64, mtps, mfps br.ClauseSet, maxUpdates int) *LPB {
+	// the threshold of the current candidate
+	var threshold int64
+	for _, point := range mtps {
+		if sum := pointSum(weights, point); threshold <= 0 || sum < threshold {
+			threshold = sum
+		}
+	}
+	updates := 0
+	for updates <= maxUpdates {
+		if ctx.Err() != nil {
+			return nil
+		}
+		changed := false
+		for _, point := range mtps {
+			if pointSum(weights, point) < threshold {
+				for _, v := range point {
+					weights[v]++
+				}
+				threshold--
+				changed = true
+				updates++
+			}
+		}
+		for _, point := range mfps {
+			if pointSum(weights, point) >= threshold {
+				for _, v := range point {
+					if weights[v] > 0 {
+						weights[v]--
+					}
+				}
+				threshold++
+				changed = true
+				updates++
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return separate(weights, mtps, mfps)
+}
+
+// pointSum returns the sum of the weights of the variables in the point.
+func pointSum(weights []int64, point br.Clause) int64 {
+	var sum int64
+	for _, v := range point {
+		sum += weights[v]
+	}
+	return sum
+}
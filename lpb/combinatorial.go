@@ -15,6 +15,7 @@
 package lpb
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
@@ -23,8 +24,9 @@ import (
 )
 
 type TreeContext struct {
-	Tree  [][]SplitNode
-	Nbvar int
+	Tree     [][]SplitNode
+	Nbvar    int
+	NumNodes int // The number of nodes in all columns
 }
 
 func NewTreeContext(nbvar int) *TreeContext {
@@ -35,6 +37,7 @@ func NewTreeContext(nbvar int) *TreeContext {
 func (c *TreeContext) AddNode(node SplitNode) int {
 	col := node.GetColumn()
 	c.Tree[col] = append(c.Tree[col], node)
+	c.NumNodes++
 	row := len(c.Tree[col]) - 1
 	node.SetRow(row)
 	return row
@@ -145,6 +148,10 @@ func (n *GenericSplitNode) SetAlreadySplit(val bool) {
 }
 
 type SplitNode interface {
+	// Split creates the children of the node. It returns ErrNotSymmetric if
+	// symmetryTest is true and the symmetry test fails. If symmetryTest is
+	// false and the node that should be shared with the upper sibling does not
+	// exist ErrNoSharedNode is returned.
 	Split(symmetryTest, cut bool) error
 
 	IsFinal() bool
@@ -211,7 +218,11 @@ func (n *MainNode) IsFinal() bool {
 	return n.Final
 }
 
-// TODO JGS: cut is being ignored because I don't really understand it :)
+// Split splits away the next variable and creates the children of the node.
+//
+// If cut is true and ϕ is already false only the lower child is created,
+// if it is already true only the upper child is created. The other child
+// would represent the same constant function and isn't required.
 func (n *MainNode) Split(symmetryTest, cut bool) error {
 	n.SetAlreadySplit(true)
 	n.MaxL = ComputeMaxL(n.GetPatterns())
@@ -318,6 +329,10 @@ func (n *AuxNode) Split(symmetryTest, cut bool) error {
 				panic("Debug error: split aux node, upperParent.upperChild is nil!")
 			}
 			n.SetUpperChild(n.GetUpperParent().GetUpperChild().GetLowerChild())
+			// ϕ is true, so the shared node must be true as well
+			if symmetryTest && (n.GetUpperChild() == nil || isFinal(n.GetUpperChild().GetPhi()) != IsTrue) {
+				return ErrNotSymmetric
+			}
 			return nil
 		}
 	}
@@ -328,8 +343,20 @@ func (n *AuxNode) Split(symmetryTest, cut bool) error {
 		}
 		n.SetUpperChild(n.GetUpperParent().GetUpperChild().GetLowerChild())
 		if n.GetUpperChild() == nil {
-			fmt.Printf("Problem in col %d, row %d, dnf %s\n", n.GetColumn(), n.GetRow(), n.GetPhi())
-			fmt.Println("Upper parent is", n.GetUpperParent().GetPhi())
+			// there is no node to share, so the variables can't be symmetric
+			if symmetryTest {
+				return ErrNotSymmetric
+			}
+			return ErrNoSharedNode
+		}
+		// the upper child is shared with the lower child of the upper sibling:
+		// the variable of this column and the variable of the next column are
+		// in the same block of equal occurrence patterns, so they should be
+		// symmetric. That is: Splitting away the variable of this column with
+		// value 1 and then the next variable with value 0 must yield the same
+		// DNF as the other way round.
+		if symmetryTest && !isSymmetric(n, n.GetUpperChild()) {
+			return ErrNotSymmetric
 		}
 		n.GetUpperChild().SetLowerParent(n)
 	} else {
@@ -358,18 +385,6 @@ func (n *AuxNode) Split(symmetryTest, cut bool) error {
 			n.SetLowerChild(lowerChild)
 		}
 	} else {
-		// here is the code for the idea in the TODO below
-		// start a go routine that computes Split with k = 0
-		// and write it to a channel
-
-		// ch := make(chan *SplitResult)
-		// if symmetryTest {
-		// 	go func() {
-		// 		ch <- Split(n, 0, false, symmetryTest)
-		// 	}()
-		// }
-
-		// end of the symmetry test code
 		if n.createMainNode() {
 			splitRes := Split(n, 1, true, symmetryTest)
 			lowerChild := NewMainNode(nil, n, splitRes.Phi, splitRes.Occurrences, n.GetContext())
@@ -383,39 +398,20 @@ func (n *AuxNode) Split(symmetryTest, cut bool) error {
 				n.GetContext(), n.LValue, n.LPrime+1)
 			n.SetLowerChild(lowerChild)
 		}
-		if symmetryTest {
-			// TODO here was the symmetry test, removed it
-			// have to think about how to do it best,
-			// I think it's not a good idea to call split again?
-			// of course we could use SplitBoth but this would needlessly create
-			// occurrence patterns, even if we don't need it
-			// I think it's best if we simply split before we even decide if we're
-			// in a main node (in a different go routine)
-			// however checking if two DNFs are equal is not that easy with this
-			// style, or is it?
-			// all clauses are sorted, so we simply must compare the length and
-			// then iterate over each element in the slice,
-			// such an implementation is already present in split_test
-			// now that I think of it that should do the trick...
-			// I also implemented the compare method: SortedEquals for Clause
-
-			// here is the code if we would want to receive the result
-			// we computed concurrently
-
-			// testDnf := <-ch
-
-			// now test it and so on
-			// if test fails return ErrNotSymmetric
-			// end of this code snippet
-
-			// TODO JGS You implemented this smmyetry test with comparing the DNFs,
-			// does that mean Split and SplitBoth don't really need the symmetry
-			// test variable?
-		}
 	}
 	return nil
 }
 
+// isSymmetric performs the symmetry test for an aux node n and the node
+// shared that is used as the upper child of n.
+// The DNF of shared must be equal to the DNF we get by splitting away the
+// variable of n with value 0. Clauses are sorted, but the order of the
+// clauses may be different.
+func isSymmetric(n, shared SplitNode) bool {
+	expected := Split(n, 0, false, false).Phi
+	return expected.DeepSortedEquals(shared.GetPhi())
+}
+
 type SplitResult struct {
 	Final       bool
 	Phi         br.ClauseSet
@@ -432,9 +428,10 @@ func NewSplitResult(final bool, phi br.ClauseSet, occurrences []*OccurrencePatte
 // away is given by the column of the node (in column k we split away variable
 // k).
 //
-// If createPatterns is true the occurrence patterns will be created
+// If createPatterns is true the occurrence patterns will be created.
 //
-// TODO implement symmetry test.
+// symmetryTest is not used by Split itself, the symmetry test is performed
+// when the split results are shared between nodes, see AuxNode.Split.
 func Split(n SplitNode, k int, createPatterns, symmetryTest bool) *SplitResult {
 	nbvar := n.GetContext().Nbvar
 	column := n.GetColumn()
@@ -446,10 +443,6 @@ func Split(n SplitNode, k int, createPatterns, symmetryTest bool) *SplitResult {
 	isResFinal := false
 	// maybe too big...
 	newDNF := br.NewClauseSet(len(n.GetPhi()))
-	if n.GetPatterns() != nil && len(n.GetPatterns()) == 0 {
-		// TODO debug, can this happen?
-		fmt.Println("Debug the split method! Weird case...")
-	}
 	// just to make clear where the variable comes from
 	variable := column
 	if k == 0 {
@@ -502,8 +495,11 @@ func Split(n SplitNode, k int, createPatterns, symmetryTest bool) *SplitResult {
 	return NewSplitResult(isResFinal, newDNF, newOccurrences)
 }
 
+// SplitBoth splits away the next variable with both values, the first result
+// is the result for value 0 (see Split), the second one for value 1.
+// If ϕ contains the empty clause both results are the same.
 //
-// TODO implement symmetry test.
+// symmetryTest is not used by SplitBoth itself, see Split.
 func SplitBoth(n SplitNode, createPatterns, symmetryTest bool) (*SplitResult, *SplitResult) {
 	nbvar := n.GetContext().Nbvar
 	column := n.GetColumn()
@@ -631,17 +627,26 @@ func SplitBoth(n SplitNode, createPatterns, symmetryTest bool) (*SplitResult, *S
 	return res1, res2
 }
 
-// ErrNotSymmetric may be returned by Split if the symmetric property
-// is violated.
+// ErrNotSymmetric is returned by CreateTree if the symmetry test is enabled
+// and two variables with equal occurrence patterns are not symmetric. In this
+// case ϕ is not a threshold function.
 var ErrNotSymmetric error = errors.New("Found variables that are not symmetric.")
 
+// ErrNoSharedNode is returned by CreateTree if the symmetry test is disabled
+// and an aux node can't share its upper child with the upper sibling because
+// that node does not exist. This can only happen if ϕ is not a threshold
+// function, with the symmetry test enabled ErrNotSymmetric is returned
+// instead.
+var ErrNoSharedNode error = errors.New("Node to share with the upper sibling does not exist.")
+
 // SplittingTree represents the tree for a DNF.
 type SplittingTree struct {
 	Root                      *MainNode    // The root node
 	Context                   *TreeContext // The context of the tree
 	Renaming, ReverseRenaming []int        // See NewSplittingTree
 	SymTest                   bool         // If true the test for symmetric variables is performed
-	Cut                       bool         // TODO JGS Not entirely sure what this is supposed to mean
+	Cut                       bool         // If true children of nodes that are already false / true are only created once, see MainNode.Split
+	MaxNodes                  int          // Maximal number of nodes in CreateTreeContext, ≤ 0 means no limit
 }
 
 // NewSplittingTree creates a new tree given the DNF ϕ.
@@ -675,8 +680,9 @@ type SplittingTree struct {
 //
 // The variables in the DNF have to be 0 <= v < nbar (so nbvar must be correct
 // and variables start with 0).
-// Also each variable should appear at least once in the DNF, what happens
-// otherwise is not tested yet.
+// Also each variable should appear at least once in the DNF, otherwise the
+// tree solver fails. CombinatorialSolver removes the other variables before
+// creating the tree.
 //
 // By default Cut and SymTest are set to true, so if you want
 // to debug better set it by hand before calling CreateTree.
@@ -748,10 +754,29 @@ func initOPs(phi br.ClauseSet, nbvar int, sortPatterns bool) (br.ClauseSet, []*O
 }
 
 // CreateTree creates the whole splitting tree and returns ErrNotSymmetric
-// if the symmetric property was violated.
+// if the symmetry test is enabled and the symmetric property was violated.
+// If the symmetry test is disabled it may return ErrNoSharedNode, see
+// SplitNode.
+// MaxNodes is ignored, see CreateTreeContext.
 //
 // Think about a concurrent approach?
 func (t *SplittingTree) CreateTree() error {
+	return t.createTree(context.Background(), 0)
+}
+
+// CreateTreeContext works as CreateTree but stops once ctx is done and
+// returns ctx.Err(). If the tree gets more than MaxNodes nodes it returns
+// ErrBudgetExceeded. In both cases the tree is not complete.
+//
+// Calling CreateTree on a complete tree does nothing, so the tree can be
+// created with CreateTreeContext before it is passed to a TreeSolver.
+func (t *SplittingTree) CreateTreeContext(ctx context.Context) error {
+	return t.createTree(ctx, t.MaxNodes)
+}
+
+// createTree creates the tree with at most maxNodes nodes (no limit if
+// maxNodes ≤ 0).
+func (t *SplittingTree) createTree(ctx context.Context, maxNodes int) error {
 	// initialize the queue, we initialize it with some size
 	// not a very good sice probably but it's something
 	waiting := make([]SplitNode, 0, t.Root.GetContext().Nbvar)
@@ -759,6 +784,12 @@ func (t *SplittingTree) CreateTree() error {
 	waiting = append(waiting, t.Root)
 	// loop while queue not empty
 	for len(waiting) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if maxNodes > 0 && t.Context.NumNodes > maxNodes {
+			return ErrBudgetExceeded
+		}
 		// get next element
 		next := waiting[0]
 		waiting[0] = nil
@@ -777,6 +808,9 @@ func (t *SplittingTree) CreateTree() error {
 			waiting = append(waiting, child2)
 		}
 	}
+	if maxNodes > 0 && t.Context.NumNodes > maxNodes {
+		return ErrBudgetExceeded
+	}
 	return nil
 }
 
@@ -801,6 +835,9 @@ func (i Interval) String() string {
 //
 // The tree is not fully created when passed to Solve, i.e. it has to be created
 // with CreateTree.
+//
+// Solve should not store state in the solver, a solver used by a cloned
+// CombinatorialSolver is called concurrently (see ClonableDNFToLPB).
 type TreeSolver interface {
 	Solve(t *SplittingTree) (*LPB, error)
 }
@@ -813,39 +850,125 @@ type TreeSolver interface {
 //
 // There are some options you may want to change, see the documentation of
 // NewCombinatorialSolver and NewSplittingTree for details.
+// MaxNodes is the maximal number of nodes in the splitting tree, see
+// SplittingTree.CreateTreeContext. By default it is 0 (no limit).
+// If FindCertificate is true and the tree solver fails a certificate that ϕ is
+// not a threshold function is computed, see Convert. This requires solving a
+// linear program, so if the solver is followed by an LPSolver (as in
+// NewHybridSolver) it should be set to false.
 //
 // It will also rename the variables in the LPB again, that is if the variables
 // were renamed for our algorithm to work it will rename the resulting LPB
 // correctly.
+//
+// The solver only stores options, Convert doesn't change them. So it can be
+// used concurrently as long as TSolver can (SimpleTreeSolver can if its
+// ColumnHandler can, each call of Solve works on its own state). Clone relies on this, if a field is added
+// that Convert changes Clone must copy it.
 type CombinatorialSolver struct {
 	TSolver                                 TreeSolver
 	SortPatterns, SortClauses, Cut, SymTest bool
+	MaxNodes                                int
+	FindCertificate                         bool
 }
 
 // NewCombinatorialSolver returns a new combinatorial solver given the
 // tree solver.
 //
-// It sets SortPatterns, SortClauses, Cut and SymTest to true, if that's
-// not what you want just change it after creating the solver.
+// It sets SortPatterns, SortClauses, Cut, SymTest and FindCertificate to
+// true, if that's not what you want just change it after creating the solver.
 // For details of these variables see NewSplittingTree were the options are
 // discussed in more detail.
 func NewCombinatorialSolver(tSolver TreeSolver) *CombinatorialSolver {
 	return &CombinatorialSolver{TSolver: tSolver,
-		SortPatterns: true,
-		SortClauses:  true,
-		Cut:          true,
-		SymTest:      true,
+		SortPatterns:    true,
+		SortClauses:     true,
+		Cut:             true,
+		SymTest:         true,
+		FindCertificate: true,
 	}
 }
 
 // Convert does everything required to compute the LPB: Create the tree,
 // start the tree solver and rename the variables if required.
+//
+// If the solver fails and FindCertificate is true it checks if ϕ is a
+// threshold function, if it is not a *NotThresholdError is returned, see
+// FindNotThresholdCertificate. Otherwise the error of the tree solver is
+// returned.
 func (s *CombinatorialSolver) Convert(phi br.ClauseSet, nbvar int) (*LPB, error) {
+	return s.ConvertContext(context.Background(), phi, nbvar)
+}
+
+// ConvertContext works as Convert but stops once ctx is done, in this case it
+// returns ctx.Err(). ctx is checked while creating the tree and passed to
+// FindNotThresholdCertificate, the tree solver itself is fast.
+// If the tree has more than MaxNodes nodes ErrBudgetExceeded is returned.
+//
+// If ϕ is false 0 ⋅ x_1 + ... + 0 ⋅ x_n ≥ 1 is returned, if it is true
+// 0 ⋅ x_1 + ... + 0 ⋅ x_n ≥ 0. Variables that don't occur in ϕ are removed
+// before the tree is created and get the coefficient 0.
+func (s *CombinatorialSolver) ConvertContext(ctx context.Context, phi br.ClauseSet, nbvar int) (*LPB, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	switch isFinal(phi) {
+	case IsFalse:
+		return NewLPB(1, make([]LPBCoeff, nbvar)), nil
+	case IsTrue:
+		return NewLPB(0, make([]LPBCoeff, nbvar)), nil
+	}
+	compact, ids := compactDNF(phi, nbvar)
+	if len(ids) == nbvar {
+		return s.convertTree(ctx, phi, nbvar)
+	}
+	res, err := s.convertTree(ctx, compact, len(ids))
+	if err != nil {
+		if cert, ok := err.(*NotThresholdError); ok {
+			return nil, cert.expand(ids, nbvar)
+		}
+		return nil, err
+	}
+	coefficients := make([]LPBCoeff, nbvar)
+	for i, v := range ids {
+		coefficients[v] = res.Coefficients[i]
+	}
+	return NewLPB(res.Threshold, coefficients), nil
+}
+
+// convertTree creates the tree for ϕ and solves it, each variable must occur
+// in ϕ.
+func (s *CombinatorialSolver) convertTree(ctx context.Context, phi br.ClauseSet, nbvar int) (*LPB, error) {
 	tree := NewSplittingTree(phi, nbvar, s.SortPatterns, s.SortClauses)
 	tree.Cut = s.Cut
 	tree.SymTest = s.SymTest
-	res, err := s.TSolver.Solve(tree)
+	tree.MaxNodes = s.MaxNodes
+	err := tree.CreateTreeContext(ctx)
+	switch {
+	case err == ErrBudgetExceeded:
+		return nil, err
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	}
+	// ErrNotSymmetric and ErrNoSharedNode are handled like an error of the tree
+	// solver
+	var res *LPB
+	if err == nil {
+		res, err = s.TSolver.Solve(tree)
+	}
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if s.FindCertificate {
+			cert, ctxErr := FindNotThresholdCertificate(ctx, phi, nbvar)
+			if ctxErr != nil {
+				return nil, ctxErr
+			}
+			if cert != nil {
+				return nil, cert
+			}
+		}
 		return nil, err
 	}
 	// undo the renaming
@@ -959,6 +1082,9 @@ func (s *SolverState) SolveConflict(column int) {
 //
 // The Init function gets called each the handler should be initialized for a
 // new tree.
+// Init should not store the tree in the handler: SimpleTreeSolver may be used
+// concurrently and then all calls share the same handler, so implementations
+// must be stateless or safe for concurrent use (see ClonableDNFToLPB).
 type ColumnHandler interface {
 	Init(t *SplittingTree)
 	ChooseCoeff(i Interval, s *SolverState, t *SplittingTree, column int) (LPBCoeff, error)
@@ -1091,6 +1217,11 @@ func (solver SimpleTreeSolver) Solve(t *SplittingTree) (*LPB, error) {
 	solver.s = NewSolverState(t)
 	k := len(solver.s.Coefficients) - 1
 	for k >= 0 {
+		// this should only happen if the symmetry test is disabled and ϕ is not
+		// a threshold function
+		if len(t.Context.Tree[k]) == 0 {
+			return nil, fmt.Errorf("Column %d in the tree is empty", k)
+		}
 		interval := solver.handler.HandleColumn(solver.s, t, k)
 		if k == 0 {
 			k--
@@ -16,10 +16,11 @@ package lpb
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
-	"strings"
 
 	br "github.com/FabianWe/boolrecognition"
 )
@@ -224,38 +225,19 @@ func NewLPB(threshold LPBCoeff, coefficients []LPBCoeff) *LPB {
 }
 
 // ParseLPB parses an LPB from the given string, if there is a syntax error
-// it returns an error != nil.
+// it returns a *SyntaxError.
 //
 // The syntax for parsing LPBs is as follows:
-// First all the coefficients are separated by a space, then the threshold
-// follows, also separated by a space.
+// First all the coefficients are separated by whitespace, then the threshold
+// follows, also separated by whitespace.
 //
 // So the LPB 2 ⋅ x1 + 1 ⋅ x2 + 1 ⋅ x3 ≥ 2 is represented by "2 1 1 2".
+//
+// Everything after a # is a comment, a line that starts with the token "c"
+// is a comment as well. Coefficients and the threshold must be ≥ 0.
+// To read several LPBs (one per line) see ReadLPBs and LPBReader.
 func ParseLPB(str string) (*LPB, error) {
-	split := strings.Split(str, " ")
-	if len(split) == 0 {
-		return nil, errors.New("LPB description is empty.")
-	}
-	threshold, err := strconv.Atoi(split[len(split)-1])
-	if err != nil {
-		return nil, err
-	}
-	coefficients := make([]LPBCoeff, len(split)-1)
-	for i, strVal := range split[:len(split)-1] {
-		if val, parseErr := strconv.Atoi(strVal); parseErr == nil {
-			// no parse error, check if value is positive
-			if val < 0 {
-				return nil, fmt.Errorf("LPB coefficients must be positive, got %d", val)
-			}
-			// it's okay, append it to the coefficients
-			coefficients[i] = LPBCoeff(val)
-		} else {
-			// parsing error
-			return nil, parseErr
-		}
-	}
-	// everything done, return result
-	return NewLPB(LPBCoeff(threshold), coefficients), nil
+	return parseLPBTokens(tokenizeLPB(str), 1)
 }
 
 func (lpb *LPB) String() string {
@@ -296,64 +278,117 @@ func (lpb *LPB) Equals(other *LPB) bool {
 	return true
 }
 
+// Eval evaluates the LPB on the point, i.e. it returns true iff the sum of
+// the coefficients of all variables that are true in the point is ≥ the
+// threshold. The point must have an entry for each coefficient.
+func (lpb *LPB) Eval(point br.BooleanVector) bool {
+	var sum LPBCoeff
+	for v, coeff := range lpb.Coefficients {
+		if point[v] {
+			sum = sum.Add(coeff)
+		}
+	}
+	return sum.Compare(lpb.Threshold) >= 0
+}
+
+// TruthTable returns the truth table of the LPB.
+func (lpb *LPB) TruthTable() *br.TruthTable {
+	return br.NewTruthTableFunc(len(lpb.Coefficients), lpb.Eval)
+}
+
 // ToDNF transforms an LPB to a DNF, algorithm as described in my bachelor
-// thesis.
+// thesis. The LPB doesn't have to be sorted.
 //
-// This function assumes that the LPB is sorted, i.e. the first value in the
-// coefficients is the greatest etc.
+// The result is the minimal DNF of the LPB, i.e. it contains exactly the
+// minimal true points of the LPB. The variables in each clause are sorted.
+// See StreamDNF for details and for a version that doesn't store all clauses.
 func (lpb *LPB) ToDNF() br.ClauseSet {
 	res := br.NewClauseSet(10)
+	lpb.StreamDNF(func(clause br.Clause) bool {
+		res = append(res, clause)
+		return true
+	})
+	return res
+}
+
+// StreamDNF computes the minimal DNF of the LPB (see ToDNF) and calls f for
+// each clause. If f returns false no more clauses are computed.
+//
+// Each clause is a new slice, so f may store it. The LPB doesn't have to be
+// sorted: The variables are sorted by their coefficients (greatest first)
+// internally and the clauses are renamed before f is called, the variables in
+// each clause are sorted.
+//
+// The clauses are computed by a depth-first search that adds the variables in
+// the order of their coefficients. As soon as the sum of a set of variables
+// reaches the threshold the set is a minimal true point (removing the last
+// variable, which has the smallest coefficient, gives a false point), so no
+// superset must be considered. A branch is pruned if the sum of all remaining
+// coefficients can't reach the threshold.
+// Thus only the current branch is stored and not all intermediate sets, the
+// memory required is linear in the number of variables.
+func (lpb *LPB) StreamDNF(f func(clause br.Clause) bool) {
+	n := len(lpb.Coefficients)
 	var sum LPBCoeff = 0
 	for _, coeff := range lpb.Coefficients {
 		sum = sum.Add(coeff)
 	}
 	// check if it represents false
 	if sum.Lesser(lpb.Threshold) {
-		// res is empty, so that's fine
-		return res
+		return
 	}
 	// check if it represents true
 	if lpb.Threshold.Compare(0) <= 0 {
-		// add an empty clause
-		c := br.NewClause(0)
-		res = append(res, c)
-		return res
+		// the empty clause
+		f(br.NewClause(0))
+		return
 	}
-
-	type setValuePair struct {
-		variables []int
-		sum       LPBCoeff
-		nextIndex int
+	// order contains the variables sorted by their coefficients
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return lpb.Coefficients[order[i]].Greater(lpb.Coefficients[order[j]])
+	})
+	coeffs := make([]LPBCoeff, n)
+	for i, v := range order {
+		coeffs[i] = lpb.Coefficients[v]
+	}
+	// remaining[i] is the sum of all coefficients starting at position i
+	remaining := make([]LPBCoeff, n+1)
+	for i := n - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1].Add(coeffs[i])
 	}
 	degree := lpb.Threshold
-	incompleteClauses := []*setValuePair{&setValuePair{nil, 0, 0}}
-
-	for len(incompleteClauses) != 0 {
-		next := incompleteClauses[0]
-		incompleteClauses[0] = nil
-		incompleteClauses = incompleteClauses[1:]
-
-		if next.sum >= degree {
-			clause := br.NewClause(len(next.variables))
-			for _, v := range next.variables {
-				clause = append(clause, v)
+	variables := make([]int, 0, n)
+	// search returns false if f returned false
+	var search func(next int, sum LPBCoeff) bool
+	search = func(next int, sum LPBCoeff) bool {
+		for i := next; i < n; i++ {
+			if sum.Add(remaining[i]).Lesser(degree) {
+				// the remaining variables can't reach the threshold
+				return true
 			}
-			res = append(res, clause)
-		} else {
-			nextInd := next.nextIndex
-			for i := nextInd; i < len(lpb.Coefficients); i++ {
-				// copy variables
-				newVariables := make([]int, len(next.variables), len(next.variables)+1)
-				copy(newVariables, next.variables)
-				newVariables = append(newVariables, i)
-				newIndex := i + 1
-				newSum := next.sum + lpb.Coefficients[i]
-				newPair := &setValuePair{newVariables, newSum, newIndex}
-				incompleteClauses = append(incompleteClauses, newPair)
+			newSum := sum.Add(coeffs[i])
+			variables = append(variables, i)
+			if newSum.Compare(degree) >= 0 {
+				clause := br.NewClause(len(variables))
+				for _, pos := range variables {
+					clause = append(clause, order[pos])
+				}
+				clause.Sort()
+				if !f(clause) {
+					return false
+				}
+			} else if !search(i+1, newSum) {
+				return false
 			}
+			variables = variables[:len(variables)-1]
 		}
+		return true
 	}
-	return res
+	search(0, 0)
 }
 
 // TODO test me
@@ -371,12 +406,23 @@ func (lpb *LPB) Rename(reverseRenaming []int) *LPB {
 	return NewLPB(lpb.Threshold, newCoeffs)
 }
 
-// DNFToLPB is an interface that provides a single method Convert.
-// This method creates a DNF representation from a DNF ϕ.
+// ErrBudgetExceeded is returned by a DNFToLPB converter if a tree
+// constructed during the conversion has more nodes than allowed, see for
+// example the MaxNodes option of CombinatorialSolver and LPSolver.
+var ErrBudgetExceeded error = errors.New("Node budget exceeded during conversion.")
+
+// DNFToLPB is an interface that provides the methods Convert and
+// ConvertContext.
+// Convert creates an LPB representation from a DNF ϕ.
 // Note that some solvers might have restrictions on how the DNF must be
 // composed (for example variables must be sorted).
+//
+// ConvertContext works as Convert but stops once ctx is done, in this case
+// it returns ctx.Err(). The deadline of ctx is also used as timeout for the
+// LP backend if it implements TimeoutLPBackend.
 type DNFToLPB interface {
 	Convert(phi br.ClauseSet, nbvar int) (*LPB, error)
+	ConvertContext(ctx context.Context, phi br.ClauseSet, nbvar int) (*LPB, error)
 }
 
 // dnfFinal is a type used to indacte if a dnf is false,
@@ -0,0 +1,109 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build golp
+// +build golp
+
+package lpb
+
+import (
+	"math"
+	"time"
+
+	"github.com/draffensperger/golp"
+)
+
+func init() {
+	LPBackends["golp"] = NewGolpLP
+}
+
+// GolpLP implements LPBackend by using lpsolve (via the golp bindings).
+//
+// It is only available if the package is built with the golp build tag,
+// lpsolve must be installed for this, see the README.
+type GolpLP struct {
+	LP *golp.LP
+}
+
+// NewGolpLP returns a new lpsolve program with numCols columns.
+func NewGolpLP(numCols int) LPBackend {
+	lp := golp.NewLP(0, numCols)
+	lp.SetVerboseLevel(golp.CRITICAL)
+	return &GolpLP{LP: lp}
+}
+
+func (lp *GolpLP) SetInt(col int, mustBeInt bool) {
+	lp.LP.SetInt(col, mustBeInt)
+}
+
+func (lp *GolpLP) AddConstraintSparse(row []LPEntry, ct ConstraintType, rhs float64) error {
+	entries := make([]golp.Entry, len(row))
+	for i, entry := range row {
+		entries[i] = golp.Entry{Col: entry.Col, Val: entry.Val}
+	}
+	var golpType golp.ConstraintType
+	switch ct {
+	case ConstraintLE:
+		golpType = golp.LE
+	case ConstraintGE:
+		golpType = golp.GE
+	default:
+		golpType = golp.EQ
+	}
+	return lp.LP.AddConstraintSparse(entries, golpType, rhs)
+}
+
+func (lp *GolpLP) SetObjFn(row []float64) {
+	lp.LP.SetObjFn(row)
+}
+
+// Return codes of solve in lpsolve 5.5, see
+// http://lpsolve.sourceforge.net/5.5/solve.htm
+// Solve maps these documented values directly, so the mapping doesn't depend
+// on how golp declares its solution type constants.
+const (
+	lpsolveOptimal    = 0
+	lpsolveSuboptimal = 1
+	lpsolveInfeasible = 2
+	lpsolveUnbounded  = 3
+)
+
+func (lp *GolpLP) Solve() LPSolutionType {
+	switch int(lp.LP.Solve()) {
+	case lpsolveOptimal:
+		return LPOptimal
+	case lpsolveSuboptimal:
+		return LPSuboptimal
+	case lpsolveInfeasible:
+		return LPInfeasible
+	case lpsolveUnbounded:
+		return LPUnbounded
+	default:
+		return LPFailed
+	}
+}
+
+func (lp *GolpLP) Variables() []float64 {
+	return lp.LP.Variables()
+}
+
+// SetTimeout sets the timeout of lpsolve, lpsolve only supports timeouts in
+// seconds, so the timeout is rounded up to the next second.
+func (lp *GolpLP) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		lp.LP.SetTimeout(0)
+		return
+	}
+	lp.LP.SetTimeout(int(math.Ceil(timeout.Seconds())))
+}
@@ -0,0 +1,114 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lpb
+
+import (
+	"context"
+	"errors"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+// ErrWrongLPB is returned by HybridSolver if the last solver in the chain
+// returned an LPB that does not represent the DNF.
+var ErrWrongLPB error = errors.New("Solver returned an LPB that does not represent the DNF.")
+
+// HybridSolver implements the DNFToLPB interface by trying a chain of
+// solvers one after another.
+//
+// The combinatorial solver is fast but known to be not complete, the linear
+// program solver is complete but slower. The idea is to try the combinatorial
+// solver first and use the linear program solver only if it fails.
+//
+// Each result is verified against the DNF with Verify, if a solver fails or returns an
+// LPB that does not represent the DNF the next solver is tried.
+// If a solver returns a *NotThresholdError the DNF is not a threshold
+// function and the error is returned immediately. If a solver returns
+// ErrBudgetExceeded the next solver is tried.
+type HybridSolver struct {
+	Solvers []DNFToLPB
+}
+
+// NewHybridSolver returns a new hybrid solver that tries the solvers in the
+// given order.
+//
+// If no solvers are given it uses a CombinatorialSolver with NewMinSolver
+// and as fallback an LPSolver with TightenNone. The combinatorial solver
+// doesn't compute certificates, the LPSolver finds the certificate while
+// solving its linear program.
+func NewHybridSolver(solvers ...DNFToLPB) *HybridSolver {
+	if len(solvers) == 0 {
+		combinatorial := NewCombinatorialSolver(NewMinSolver())
+		combinatorial.FindCertificate = false
+		solvers = []DNFToLPB{combinatorial, NewLPSolver(TightenNone)}
+	}
+	return &HybridSolver{Solvers: solvers}
+}
+
+// Convert converts the DNF by trying all solvers in the chain, see
+// ConvertReport.
+func (s *HybridSolver) Convert(phi br.ClauseSet, nbvar int) (*LPB, error) {
+	res, _, err := s.ConvertReport(phi, nbvar)
+	return res, err
+}
+
+// ConvertContext works as Convert but stops once ctx is done, see
+// ConvertReportContext.
+func (s *HybridSolver) ConvertContext(ctx context.Context, phi br.ClauseSet, nbvar int) (*LPB, error) {
+	res, _, err := s.ConvertReportContext(ctx, phi, nbvar)
+	return res, err
+}
+
+// ConvertReport works as Convert but also returns the index of the solver in
+// Solvers that computed the result.
+//
+// The index is -1 if no solver succeeded. If the DNF is not a threshold
+// function the index is the index of the solver that returned the
+// *NotThresholdError.
+// If all solvers fail the error of the last solver is returned.
+func (s *HybridSolver) ConvertReport(phi br.ClauseSet, nbvar int) (*LPB, int, error) {
+	return s.ConvertReportContext(context.Background(), phi, nbvar)
+}
+
+// ConvertReportContext works as ConvertReport but each solver is called with
+// ConvertContext. Once ctx is done no other solver is tried and index -1 and
+// ctx.Err() are returned.
+func (s *HybridSolver) ConvertReportContext(ctx context.Context, phi br.ClauseSet, nbvar int) (*LPB, int, error) {
+	if len(s.Solvers) == 0 {
+		return nil, -1, errors.New("No solvers in hybrid solver")
+	}
+	var err error
+	for i, solver := range s.Solvers {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, -1, ctxErr
+		}
+		var res *LPB
+		res, err = solver.ConvertContext(ctx, phi, nbvar)
+		if err != nil {
+			if _, ok := err.(*NotThresholdError); ok {
+				return nil, i, err
+			}
+			continue
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, -1, ctxErr
+		}
+		if Verify(res, phi, nbvar) == nil {
+			return res, i, nil
+		}
+		err = ErrWrongLPB
+	}
+	return nil, -1, err
+}
@@ -16,14 +16,16 @@
 package lpb
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"math"
+	"math/big"
+	"runtime"
 	"sort"
 	"sync"
+	"time"
 
 	br "github.com/FabianWe/boolrecognition"
-	"github.com/draffensperger/golp"
 )
 
 // debug is used to panic in some conditions, if tested properly set to false.
@@ -44,9 +46,13 @@ type DNFTreeNodeContent struct {
 
 // A DNFTree is a collection of DNFTreeNodeContent objects.
 // The root note is stored on position 0.
+//
+// MaxNodes is the maximal number of nodes BuildTreeContext creates, if it is
+// ≤ 0 there is no limit.
 type DNFTree struct {
-	Content []*DNFTreeNodeContent
-	Nbvar   int
+	Content  []*DNFTreeNodeContent
+	Nbvar    int
+	MaxNodes int
 }
 
 // NewDNFTree returns an empty tree containing no nodes.
@@ -133,7 +139,21 @@ func (tree *DNFTree) Split(nodeID int) (*LPSplitResult, *LPSplitResult) {
 }
 
 // BuildTree will build the whole tree. The root note must be set already.
+// MaxNodes is ignored, see BuildTreeContext.
 func (tree *DNFTree) BuildTree() {
+	tree.buildTree(context.Background(), 0)
+}
+
+// BuildTreeContext works as BuildTree but stops once ctx is done and returns
+// ctx.Err(). If the tree gets more than MaxNodes nodes it returns
+// ErrBudgetExceeded. In both cases the tree is not complete.
+func (tree *DNFTree) BuildTreeContext(ctx context.Context) error {
+	return tree.buildTree(ctx, tree.MaxNodes)
+}
+
+// buildTree builds the tree with at most maxNodes nodes (no limit if
+// maxNodes ≤ 0).
+func (tree *DNFTree) buildTree(ctx context.Context, maxNodes int) error {
 	if debug {
 		if len(tree.Content) != 1 {
 			panic("Expected a tree containing exactly one node (the root) in BuildTree")
@@ -141,12 +161,18 @@ func (tree *DNFTree) BuildTree() {
 	}
 	if tree.Content[0].final {
 		// for true and false there is nothing to do
-		return
+		return nil
 	}
 	// create a queue that stores the node ids that must be explored
 	// add first node (root) to it
 	waiting := []int{0}
 	for len(waiting) != 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if maxNodes > 0 && len(tree.Content) > maxNodes {
+			return ErrBudgetExceeded
+		}
 		nextID := waiting[0]
 		waiting = waiting[1:]
 		next := tree.Content[nextID]
@@ -173,15 +199,19 @@ func (tree *DNFTree) BuildTree() {
 				waiting = append(waiting, rightID)
 			}
 		} else {
-			rightID := tree.CreateRightChild(nextID, second.Phi, true)
+			rightID := tree.CreateRightChild(nextID, second.Phi, false)
 			waiting = append(waiting, rightID)
 		}
 	}
+	if maxNodes > 0 && len(tree.Content) > maxNodes {
+		return ErrBudgetExceeded
+	}
+	return nil
 }
 
-func (tree *DNFTree) IsImplicant(mtp br.BooleanVector) bool {
+func (tree *DNFTree) IsImplicant(mtp *br.BitVector) bool {
 	uID := 0
-	for k := 0; k < len(mtp); k++ {
+	for k := 0; k < mtp.Len(); k++ {
 		u := tree.Content[uID]
 
 		if tree.IsLeaf(uID) {
@@ -189,7 +219,7 @@ func (tree *DNFTree) IsImplicant(mtp br.BooleanVector) bool {
 		}
 
 		leftChild, rightChild := u.leftChild, u.rightChild
-		if mtp[k] {
+		if mtp.Test(k) {
 			if leftChild >= 0 {
 				uID = leftChild
 				continue
@@ -218,52 +248,120 @@ func (tree *DNFTree) IsImplicant(mtp br.BooleanVector) bool {
 	return true
 }
 
-func (tree *DNFTree) IsRegular(mtps []br.BooleanVector) bool {
-	numRuns := tree.Nbvar - 1
-	res := true
-	// we will do this concurrently:
-	// for each mtp iterate over all variable combinations and perform the test
-	// and write the result to a channel
-	// this also has some drawback: we need to wait for all mtps to finish
-	// otherwise we would need some context wish would be too much here
-	// so they all must write a result, even if one already returns false...
-	report := make(chan bool, 10)
-	// channel to report once we read all results
-	done := make(chan bool)
-	go func() {
-		for i := 0; i < len(mtps); i++ {
-			nxt := <-report
-			if !nxt {
-				res = false
-			}
-		}
-		done <- true
-	}()
-	for k := 0; k < len(mtps); k++ {
-		go func(index int) {
-			mtp := mtps[index]
-			check := true
-			for i := 0; i < numRuns; i++ {
-				if (!mtp[i]) && (mtp[i+1]) {
-					// change the positions in the point, after the implicant test
-					// we will change them again
-					mtp[i] = true
-					mtp[i+1] = false
-					isImplicant := tree.IsImplicant(mtp)
-					mtp[i] = false
-					mtp[i+1] = true
-					if !isImplicant {
-						check = false
-						break
+// IsRegular checks if the regularity condition holds for all minimal true
+// points, see RegularityViolation. The tree must already be built with
+// BuildTree and the variables must be sorted according to the Winder matrix.
+//
+// mtps is not changed and can be read concurrently by other goroutines.
+// Once ctx is done it returns false and ctx.Err().
+func (tree *DNFTree) IsRegular(ctx context.Context, mtps []*br.BitVector) (bool, error) {
+	violation, err := tree.RegularityViolation(ctx, mtps)
+	if err != nil {
+		return false, err
+	}
+	return violation == nil, nil
+}
+
+// RegularityViolation works as IsRegular but returns the violation of the
+// regularity condition: a minimal true point and the pair i, i + 1 s.t.
+// swapping them yields a false point. If the condition holds for all points
+// it returns nil.
+//
+// The points are tested concurrently by a pool of at most runtime.NumCPU()
+// workers. Each worker tests its own copy of a point, so mtps is not changed.
+// Once a point violates the condition no further points are handed to the
+// workers, the points before it are still tested. So the result is the
+// violation of the first point in mtps that violates the condition, as in a
+// sequential test.
+// If ctx is done before the test is finished it returns ctx.Err().
+func (tree *DNFTree) RegularityViolation(ctx context.Context, mtps []*br.BitVector) (*RegularityCertificate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var mutex sync.Mutex
+	var violation *RegularityCertificate
+	violationIndex := -1
+	// found gets closed once the first violation was found
+	found := make(chan struct{})
+	var foundOnce sync.Once
+	jobs := make(chan int)
+	workers := runtime.NumCPU()
+	if workers > len(mtps) {
+		workers = len(mtps)
+	}
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				mutex.Lock()
+				skip := violationIndex >= 0 && index > violationIndex
+				mutex.Unlock()
+				if skip {
+					continue
+				}
+				mtp := mtps[index]
+				if first := tree.regularityViolation(mtp.Clone()); first >= 0 {
+					mutex.Lock()
+					if violationIndex < 0 || index < violationIndex {
+						violationIndex = index
+						violation = &RegularityCertificate{MTP: mtp.ToBooleanVector(),
+							First:  first,
+							Second: first + 1,
+						}
 					}
+					mutex.Unlock()
+					foundOnce.Do(func() { close(found) })
 				}
 			}
-			report <- check
-		}(k)
+		}()
 	}
-	// wait until all results are there
-	<-done
-	return res
+send:
+	for index := range mtps {
+		select {
+		case jobs <- index:
+		case <-found:
+			break send
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return violation, nil
+}
+
+// regularityViolation checks the regularity condition for a minimal true
+// point: For each i with mtp[i] = 0 and mtp[i + 1] = 1 swapping i and i + 1
+// must yield a true point.
+// It returns the first i for which this does not hold or -1 if there is no
+// such i.
+//
+// The values in mtp are changed during the test, but are restored before the
+// function returns.
+func (tree *DNFTree) regularityViolation(mtp *br.BitVector) int {
+	for i := 0; i < tree.Nbvar-1; i++ {
+		if (!mtp.Test(i)) && mtp.Test(i+1) {
+			// change the positions in the point, after the implicant test
+			// we will change them again
+			mtp.Set(i)
+			mtp.Clear(i + 1)
+			isImplicant := tree.IsImplicant(mtp)
+			mtp.Clear(i)
+			mtp.Set(i + 1)
+			if !isImplicant {
+				return i
+			}
+		}
+	}
+	return -1
 }
 
 // TightenMode describes different modes to tighten the linear program
@@ -278,12 +376,45 @@ const (
 	TightenAll                           // Add additional constraints between all variable pairs
 )
 
+// Objective describes which value should be minimized when solving the
+// linear program.
+//
+// If an objective other than ObjectiveNone is used the lp must be solved with
+// integer constraints, so this is slower. But you get the smallest integer
+// realization of the threshold function (w.r.t. the objective).
+type Objective int
+
+const (
+	ObjectiveNone      Objective = iota // No objective, any solution is fine
+	ObjectiveThreshold                  // Minimize the threshold
+	ObjectiveSum                        // Minimize the sum of all coefficients
+	ObjectiveMax                        // Minimize the greatest coefficient
+)
+
+func (o Objective) String() string {
+	switch o {
+	case ObjectiveNone:
+		return "none"
+	case ObjectiveThreshold:
+		return "threshold"
+	case ObjectiveSum:
+		return "sum"
+	case ObjectiveMax:
+		return "max"
+	default:
+		return "unknown"
+	}
+}
+
 type LinearProgram struct {
 	Renaming, ReverseRenaming []int
 	Tree                      *DNFTree
 	Winder                    br.WinderMatrix
-	LP                        *golp.LP
-	MFPs, MTPs                []br.BooleanVector
+	LP                        LPBackend
+	Backend                   LPBackendFactory
+	Exact                     bool
+	Objective                 Objective
+	MFPs, MTPs                []*br.BitVector
 	Phi                       br.ClauseSet
 	Nbvar                     int
 }
@@ -321,6 +452,10 @@ type LinearProgram struct {
 // and variables start with 0).
 // Also each variable should appear at least once in the DNF, what happens
 // otherwise is not tested yet.
+//
+// The program gets solved with DefaultLPBackend, set Backend to use another
+// backend. Exact is set to true and Objective to ObjectiveNone, see
+// LinearProgram.Solve.
 func NewLinearProgram(phi br.ClauseSet, nbvar int, sortMatrix, sortClauses bool) *LinearProgram {
 	tree := NewDNFTree(nbvar)
 	newDNF, winder, renaming, reverseRenaming := InitLP(phi, nbvar, sortMatrix)
@@ -339,6 +474,9 @@ func NewLinearProgram(phi br.ClauseSet, nbvar int, sortMatrix, sortClauses bool)
 		Tree:            tree,
 		Winder:          winder,
 		LP:              nil,
+		Backend:         DefaultLPBackend,
+		Exact:           true,
+		Objective:       ObjectiveNone,
 		MFPs:            nil,
 		MTPs:            nil,
 		Phi:             newDNF,
@@ -389,40 +527,89 @@ func InitLP(phi br.ClauseSet, nbvar int, sortMatrix bool) (br.ClauseSet, br.Wind
 	return newDNF, winder, renaming, reverseRenaming
 }
 
+// Solve computes the MTPs and MFPs, sets up the linear program and solves it.
+// If regTest is true it will first check if the DNF is regular.
+//
+// If Exact is true and the backend implements ExactLPBackend the linear
+// program relaxation is solved with exact rational arithmetic and the
+// solution gets scaled to integer values, see SolveLP. Otherwise all variables
+// are forced to be integers, this is much slower.
+// If Objective is not ObjectiveNone integer constraints are always required.
+//
+// If the DNF is not regular or the lp is infeasible a *NotThresholdError is
+// returned that contains a certificate, see NotThresholdError.
 func (lp LinearProgram) Solve(tighten TightenMode, regTest bool) (*LPB, error) {
+	return lp.SolveContext(context.Background(), tighten, regTest)
+}
+
+// SolveContext works as Solve but stops once ctx is done, in this case it
+// returns ctx.Err().
+//
+// ctx is checked while building the tree and during the regularity test,
+// the deadline of ctx is used as timeout for the backend if it implements
+// TimeoutLPBackend. If the tree has more than Tree.MaxNodes nodes
+// ErrBudgetExceeded is returned.
+func (lp LinearProgram) SolveContext(ctx context.Context, tighten TightenMode, regTest bool) (*LPB, error) {
+	if lp.Backend == nil {
+		lp.Backend = DefaultLPBackend
+	}
 	// create minimal true points
 	mtps := ComputeMTPs(lp.Phi, lp.Nbvar)
 	lp.MTPs = mtps
 	// if regularity test should be beformed create the DNF tree
 	if regTest {
-		lp.Tree.BuildTree()
-		if !lp.Tree.IsRegular(mtps) {
-			return nil, errors.New("DNF is not regular")
+		if err := lp.Tree.BuildTreeContext(ctx); err != nil {
+			return nil, err
+		}
+		violation, err := lp.Tree.RegularityViolation(ctx, mtps)
+		if err != nil {
+			return nil, err
+		}
+		if violation != nil {
+			return nil, lp.regularityError(ctx, mtps, violation)
 		}
 	}
 	// compute maximal false points
 	mfps := ComputeMFPs(mtps, true)
 	lp.MFPs = mfps
 	// setup the linear program
-	program, setupErr := FormulateLP(mtps, mfps, lp.Nbvar, lp.Winder, tighten)
+	integral := !lp.Exact || lp.Objective != ObjectiveNone
+	program, setupErr := FormulateLP(mtps, mfps, lp.Nbvar, lp.Winder, tighten, lp.Objective, lp.Backend, integral)
 	if setupErr != nil {
 		return nil, setupErr
 	}
 	lp.LP = program
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline {
+		if withTimeout, ok := program.(TimeoutLPBackend); ok {
+			withTimeout.SetTimeout(time.Until(deadline))
+		}
+	}
 	// try to convert it
-	return SolveLP(program)
+	// if that fails check if the DNF is a threshold function, if not
+	// return a certificate
+	res, err := SolveLP(program, lp.Nbvar)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, lp.infeasibleError(ctx, err)
+	}
+	return res, nil
 }
 
 // ComputeMTPs computes the set of minimal true points of a minimal ϕ.
 // Since ϕ is minimal this is easy: Each clause defines exactly one minimal
 // true point.
-func ComputeMTPs(phi br.ClauseSet, nbvar int) []br.BooleanVector {
-	res := make([]br.BooleanVector, len(phi))
+func ComputeMTPs(phi br.ClauseSet, nbvar int) []*br.BitVector {
+	res := make([]*br.BitVector, len(phi))
 	for i, clause := range phi {
-		point := br.NewBooleanVector(nbvar)
+		point := br.NewBitVector(nbvar)
 		res[i] = point
 		for _, v := range clause {
-			point[v] = true
+			point.Set(v)
 		}
 	}
 	return res
@@ -430,29 +617,17 @@ func ComputeMTPs(phi br.ClauseSet, nbvar int) []br.BooleanVector {
 
 // TODO test this with 0, I don't know what happens to the wait group
 // otherwise, or just never call it with a DNF with zero clauses
-func ComputeMFPs(mtps []br.BooleanVector, sortPoints bool) []br.BooleanVector {
+func ComputeMFPs(mtps []*br.BitVector, sortPoints bool) []*br.BitVector {
 	// first sort the mtps
 	if sortPoints {
 		cmp := func(i, j int) bool {
 			p1, p2 := mtps[i], mtps[j]
 			if debug {
-				if len(p1) != len(p2) {
+				if p1.Len() != p2.Len() {
 					panic("MTPS must be of same length in ComputeMFPs")
 				}
 			}
-			size := len(p1)
-			for k := 0; k < size; k++ {
-				val1, val2 := p1[k], p2[k]
-				if (!val1) && val2 {
-					return true
-				} else if val1 && (!val2) {
-					return false
-				}
-			}
-			if debug {
-				panic("Must not reach this state in ComputeMFPs")
-			}
-			return false
+			return p1.Compare(p2) < 0
 		}
 		sort.Slice(mtps, cmp)
 	}
@@ -462,14 +637,10 @@ func ComputeMFPs(mtps []br.BooleanVector, sortPoints bool) []br.BooleanVector {
 	nu := make([]int, len(mtps))
 	for i := 1; i < len(mtps); i++ {
 		go func(index int) {
-			vars := len(mtps[index])
-			for j := 0; j < vars; j++ {
-				val1 := mtps[index-1][j]
-				val2 := mtps[index][j]
-				if (!val1) && val2 {
-					nu[index] = j + 1
-					break
-				}
+			// the points are sorted, so at the first position where the points
+			// differ the previous point is 0 and this point is 1
+			if j := mtps[index-1].FirstDifference(mtps[index]); j >= 0 {
+				nu[index] = j + 1
 			}
 			wg.Done()
 		}(i)
@@ -479,11 +650,11 @@ func ComputeMFPs(mtps []br.BooleanVector, sortPoints bool) []br.BooleanVector {
 	// create the actual points, again we do that concurrently and communicate
 	// via a channel
 	// we range over that channel so we must not forget to close it!
-	res := make([]br.BooleanVector, 0, 10)
+	res := make([]*br.BitVector, 0, 10)
 	// start a function that listens on the channel and adds all points to the
 	// result
 	// we use a done channel to signal when all points have been added
-	resChan := make(chan br.BooleanVector, 10)
+	resChan := make(chan *br.BitVector, 10)
 	done := make(chan bool)
 	go func() {
 		for point := range resChan {
@@ -498,23 +669,21 @@ func ComputeMFPs(mtps []br.BooleanVector, sortPoints bool) []br.BooleanVector {
 	for i := 0; i < len(mtps); i++ {
 		go func(index int) {
 			point := mtps[index]
-			vars := len(point)
-			for j := nu[index]; j < vars; j++ {
-				if point[j] {
+			vars := point.Len()
+			for j := point.NextSet(nu[index]); j >= 0; j = point.NextSet(j + 1) {
 
-					if debug {
-						if nu[index] > j {
-							panic("nu[i] must be <= j in ComputeMFPs")
-						}
+				if debug {
+					if nu[index] > j {
+						panic("nu[i] must be <= j in ComputeMFPs")
 					}
+				}
 
-					newPoint := point.Clone()
-					newPoint[j] = false
-					for k := j + 1; k < vars; k++ {
-						newPoint[k] = true
-					}
-					resChan <- newPoint
+				newPoint := point.Clone()
+				newPoint.Clear(j)
+				for k := j + 1; k < vars; k++ {
+					newPoint.Set(k)
 				}
+				resChan <- newPoint
 			}
 			wg.Done()
 		}(i)
@@ -528,8 +697,10 @@ func ComputeMFPs(mtps []br.BooleanVector, sortPoints bool) []br.BooleanVector {
 
 // FormulateLP will formulate the linear program to solve.
 // It will set the following constraings:
-// 1. All variables must be of type int (note that this is really bad for
-// the runtime of lpsolve)
+// 1. If integral is true or the backend created by newLP doesn't implement
+// ExactLPBackend: All variables must be of type int (note that this
+// is really bad for the runtime of the solver). If the backend returns exact
+// solutions this is not required, see SolveLP.
 // 2. For each minimal true point (a1, ..., ak) where ai are the variables
 // that are true a constraint that says that the sum of
 // all variables must be ≥ the degree
@@ -539,7 +710,7 @@ func ComputeMFPs(mtps []br.BooleanVector, sortPoints bool) []br.BooleanVector {
 // that are true a constraint that says that the sum of all variables
 // must be < the degree:
 // a1 + ... + ak < d
-// because lpsolve only allows ≤ we transform this to
+// because the solvers only allow ≤ we transform this to
 // a1 + ... + ak ≤ d - 1 ⇔ a1 + ... + ak -  ≤ -1
 //
 // The additional constraints depend on the mode:
@@ -547,41 +718,58 @@ func ComputeMFPs(mtps []br.BooleanVector, sortPoints bool) []br.BooleanVector {
 // We know that it must always hold that w(i) ≥ w(i+1), but it could also
 // be w(i) = w(i+1), we find that out by comparing the Winder matrix entries.
 // So we have w(i) ≥ w(i+1) ⇔ w(i) - w(i+1) >= 0 or w(i) - w(i+1) = 0.
+//
+// The objective function depends on objective: For ObjectiveNone it is
+// constant zero, for ObjectiveThreshold it is d and for ObjectiveSum it is
+// w(0) + ... + w(nbvar - 1).
+// For ObjectiveMax we add another variable m (with id nbvar + 1) and the
+// constraints m ≥ w(i) for all i, the objective is m.
+// If you want a minimal LPB you should set integral to true, otherwise
+// the solution of the relaxation is not guaranteed to be minimal.
+//
+// The program is created with newLP, if newLP is nil DefaultLPBackend is used.
 // TODO we can make this easily concurrent
-func FormulateLP(mtps, mfps []br.BooleanVector, nbvar int, winder br.WinderMatrix, tighten TightenMode) (*golp.LP, error) {
+func FormulateLP(mtps, mfps []*br.BitVector, nbvar int, winder br.WinderMatrix, tighten TightenMode, objective Objective, newLP LPBackendFactory, integral bool) (LPBackend, error) {
 	// go uses zero based ids, so all variables have ids between 0 and nbvar -1
 	// the degree has id nbvar
 	degreeID := nbvar
-	lp := golp.NewLP(0, nbvar+1)
-	// set int constraing on all variables
-	for column := 0; column < nbvar+1; column++ {
-		lp.SetInt(column, true)
+	numCols := nbvar + 1
+	// for the max objective we need one additional column
+	if objective == ObjectiveMax {
+		numCols++
+	}
+	if newLP == nil {
+		newLP = DefaultLPBackend
+	}
+	lp := newLP(numCols)
+	// set int constraing on all variables, without exact solutions SolveLP
+	// requires integer values
+	if _, isExact := lp.(ExactLPBackend); integral || !isExact {
+		for column := 0; column < numCols; column++ {
+			lp.SetInt(column, true)
+		}
 	}
 	for _, mtp := range mtps {
 		// now add the constraint
-		row := make([]golp.Entry, 0, nbvar+1)
-		for j, val := range mtp {
-			if val {
-				row = append(row, golp.Entry{Col: j, Val: 1})
-			}
+		row := make([]LPEntry, 0, mtp.Count()+1)
+		for j := mtp.NextSet(0); j >= 0; j = mtp.NextSet(j + 1) {
+			row = append(row, LPEntry{Col: j, Val: 1})
 		}
 		// add -d
-		row = append(row, golp.Entry{Col: degreeID, Val: -1})
+		row = append(row, LPEntry{Col: degreeID, Val: -1})
 		// add the row
-		if err := lp.AddConstraintSparse(row, golp.GE, 0); err != nil {
+		if err := lp.AddConstraintSparse(row, ConstraintGE, 0); err != nil {
 			return nil, err
 		}
 	}
 	for _, mfp := range mfps {
-		row := make([]golp.Entry, 0, nbvar+1)
-		for j, val := range mfp {
-			if val {
-				row = append(row, golp.Entry{Col: j, Val: 1})
-			}
+		row := make([]LPEntry, 0, mfp.Count()+1)
+		for j := mfp.NextSet(0); j >= 0; j = mfp.NextSet(j + 1) {
+			row = append(row, LPEntry{Col: j, Val: 1})
 		}
 		// add -d
-		row = append(row, golp.Entry{Col: degreeID, Val: -1})
-		if err := lp.AddConstraintSparse(row, golp.LE, -1); err != nil {
+		row = append(row, LPEntry{Col: degreeID, Val: -1})
+		if err := lp.AddConstraintSparse(row, ConstraintLE, -1); err != nil {
 			return nil, err
 		}
 	}
@@ -591,12 +779,12 @@ func FormulateLP(mtps, mfps []br.BooleanVector, nbvar int, winder br.WinderMatri
 		// add a constraint for neighbouring variables
 		// we already know that w(i) ≥ w(i+1), but we could already conclude
 		// that they must be equal
-		entry1 := golp.Entry{Col: -1, Val: 1}
-		entry2 := golp.Entry{Col: -1, Val: -1}
+		entry1 := LPEntry{Col: -1, Val: 1}
+		entry2 := LPEntry{Col: -1, Val: -1}
 		for i := 1; i < nbvar; i++ {
 			// compare both rows
 			compRes := br.CompareMatrixEntry(winder[i-1], winder[i])
-			var constraint golp.ConstraintType = golp.GE
+			var constraint ConstraintType = ConstraintGE
 
 			if debug {
 				if compRes < 0 {
@@ -605,12 +793,12 @@ func FormulateLP(mtps, mfps []br.BooleanVector, nbvar int, winder br.WinderMatri
 			}
 
 			if compRes == 0 {
-				constraint = golp.EQ
+				constraint = ConstraintEQ
 			}
 			// now update the row and add the constraint
 			entry1.Col = i - 1
 			entry2.Col = i
-			if err := lp.AddConstraintSparse([]golp.Entry{entry1, entry2}, constraint, 0); err != nil {
+			if err := lp.AddConstraintSparse([]LPEntry{entry1, entry2}, constraint, 0); err != nil {
 				return nil, err
 			}
 		}
@@ -637,8 +825,8 @@ func FormulateLP(mtps, mfps []br.BooleanVector, nbvar int, winder br.WinderMatri
 
 			precomputed[i-1] = compRes
 		}
-		entry1 := golp.Entry{Col: -1, Val: 1}
-		entry2 := golp.Entry{Col: -1, Val: -1}
+		entry1 := LPEntry{Col: -1, Val: 1}
+		entry2 := LPEntry{Col: -1, Val: -1}
 		// now add all variable pair results
 		for i := 0; i < nbvar; i++ {
 			entry1.Col = i
@@ -649,69 +837,145 @@ func FormulateLP(mtps, mfps []br.BooleanVector, nbvar int, winder br.WinderMatri
 			for ; j < nbvar && precomputed[j-1] == 0; j++ {
 				// add eq constraing
 				entry2.Col = j
-				if err := lp.AddConstraintSparse([]golp.Entry{entry1, entry2}, golp.EQ, 0); err != nil {
+				if err := lp.AddConstraintSparse([]LPEntry{entry1, entry2}, ConstraintEQ, 0); err != nil {
 					return nil, err
 				}
 			}
 			// for all remaining j simply add ≥ constraint
 			for ; j < nbvar; j++ {
 				entry2.Col = j
-				if err := lp.AddConstraintSparse([]golp.Entry{entry1, entry2}, golp.GE, 0); err != nil {
+				if err := lp.AddConstraintSparse([]LPEntry{entry1, entry2}, ConstraintGE, 0); err != nil {
 					return nil, err
 				}
 			}
 		}
 	}
-	// TODO some objective must be set
-	// I only came up with this, however this will also keep the Coefficients
-	// small...
-	obj := make([]float64, nbvar+1)
-	// for i := 0; i < nbvar+1; i++ {
-	// 	obj[i] = 1.0
-	// }
-	obj[0] = 0.0
+	obj := make([]float64, numCols)
+	switch objective {
+	case ObjectiveThreshold:
+		obj[degreeID] = 1
+	case ObjectiveSum:
+		for i := 0; i < nbvar; i++ {
+			obj[i] = 1
+		}
+	case ObjectiveMax:
+		maxID := nbvar + 1
+		obj[maxID] = 1
+		// add m - w(i) ≥ 0 for all i
+		entry1 := LPEntry{Col: maxID, Val: 1}
+		entry2 := LPEntry{Col: -1, Val: -1}
+		for i := 0; i < nbvar; i++ {
+			entry2.Col = i
+			if err := lp.AddConstraintSparse([]LPEntry{entry1, entry2}, ConstraintGE, 0); err != nil {
+				return nil, err
+			}
+		}
+	}
 	lp.SetObjFn(obj)
 	return lp, nil
 }
 
+// SolveLP solves the linear program and creates the LPB from the solution.
+//
+// If lp implements ExactLPBackend the solution is read as rational numbers.
+// The solution doesn't have to be integral: all values get multiplied with the
+// least common denominator (and then divided by the gcd of the results).
+// Because the maximal false points must be ≤ d - 1 this is still a solution,
+// so there are no rounding errors.
+// Otherwise the float values are rounded to the nearest integer, in this case
+// the lp must contain integer constraints for all variables.
+//
+// nbvar is the number of variables in the DNF, the lp may contain additional
+// columns after the threshold (see FormulateLP) that are ignored.
+//
 // TODO only call if there is at least one variable
-func SolveLP(lp *golp.LP) (*LPB, error) {
-	lp.SetVerboseLevel(golp.CRITICAL)
+func SolveLP(lp LPBackend, nbvar int) (*LPB, error) {
 	convRes := lp.Solve()
 	// TODO I've added suboptiomal, this should be ok as well?
-	// TODO seems the constants in golp are wrong...
-	// we should use them but it's broken :(
-	if convRes != golp.OPTIMAL && convRes != golp.SUBOPTIMAL {
-		return nil, fmt.Errorf("Can't solve linear program, lpsolve solution type is %v", convRes)
+	if convRes != LPOptimal && convRes != LPSuboptimal {
+		return nil, fmt.Errorf("Can't solve linear program, solution type is %v", convRes)
+	}
+	if exact, isExact := lp.(ExactLPBackend); isExact {
+		return lpbFromRats(exact.RatVariables()[:nbvar+1])
 	}
-	vars := lp.Variables()
+	vars := lp.Variables()[:nbvar+1]
 	coeffs := make([]LPBCoeff, len(vars)-1)
 	for i, asFloat := range vars[:len(vars)-1] {
-		// just to be sure
-		asFloat = math.Floor(asFloat)
+		// just to be sure, lpsolve might return something like 2.9999999
+		asFloat = math.Floor(asFloat + 0.5)
 		coeff := LPBCoeff(asFloat)
 		coeffs[i] = coeff
 	}
-	threshold := LPBCoeff(vars[len(vars)-1])
+	threshold := LPBCoeff(math.Floor(vars[len(vars)-1] + 0.5))
 	return NewLPB(threshold, coeffs), nil
 }
 
+// lpbFromRats creates the LPB from the rational solution of the lp (the last
+// value is the threshold), see SolveLP.
+func lpbFromRats(vars []*big.Rat) (*LPB, error) {
+	// compute the least common denominator
+	lcd := big.NewInt(1)
+	gcd := new(big.Int)
+	for _, val := range vars {
+		denom := val.Denom()
+		gcd.GCD(nil, nil, lcd, denom)
+		lcd.Mul(lcd, new(big.Int).Quo(denom, gcd))
+	}
+	scaled := make([]*big.Int, len(vars))
+	gcd.SetInt64(0)
+	for i, val := range vars {
+		num := new(big.Int).Mul(val.Num(), new(big.Int).Quo(lcd, val.Denom()))
+		scaled[i] = num
+		gcd.GCD(nil, nil, gcd, new(big.Int).Abs(num))
+	}
+	values := make([]LPBCoeff, len(vars))
+	for i, val := range scaled {
+		if gcd.Sign() != 0 {
+			val.Quo(val, gcd)
+		}
+		if !val.IsInt64() || val.Int64() != int64(int(val.Int64())) {
+			return nil, fmt.Errorf("Value %s of the lp solution is too big", val)
+		}
+		values[i] = LPBCoeff(val.Int64())
+	}
+	return NewLPB(values[len(values)-1], values[:len(values)-1]), nil
+}
+
 // LPSolver implements the DNFToLPB interface by using the linear programming
 // algorithm.
 //
 // Ther are some options you can change, see NewLPSolver, NewLinearProgram and
 // NewLinearProgram.Solve for more details.
+// Backend is the LP backend used to solve the program, by default this is
+// DefaultLPBackend (pure Go), see LPBackends for other backends.
+// If Exact is true and the backend supports it the lp is solved with exact
+// rational arithmetic, see LinearProgram.Solve.
+// Objective describes which value of the LPB should be minimized, by default
+// this is ObjectiveNone.
 //
 // It will also rename the variables in the LPB again, that is if the variables
 // were renamed for our algorithm to work it will rename the resulting LPB
 // correctly.
+//
+// MaxNodes is the maximal number of nodes in the tree for the regularity test,
+// see DNFTree. By default it is 0 (no limit).
+//
+// The solver only stores options, Convert doesn't change them and creates a
+// new backend with Backend for each lp. So it can be used concurrently as long
+// as Backend can be called concurrently (NewSimplexLP can). Clone relies on
+// this, if a field is added that Convert changes Clone must copy it.
 type LPSolver struct {
 	SortMatrix, SortClauses, RegTest bool
 	Tighten                          TightenMode
+	Backend                          LPBackendFactory
+	Exact                            bool
+	Objective                        Objective
+	MaxNodes                         int
 }
 
-// NewLPSolver returns a new LPSolver with SortMatrix, SortClauses and RegTest
-// set to true.
+// NewLPSolver returns a new LPSolver with SortMatrix, SortClauses, RegTest and
+// Exact set to true, Backend set to DefaultLPBackend and Objective set to
+// ObjectiveNone.
 //
 // For details of these variables see NewLinearProgram and LinearProgram.Solve
 // for more details.
@@ -723,6 +987,9 @@ func NewLPSolver(tighten TightenMode) *LPSolver {
 		SortClauses: true,
 		RegTest:     true,
 		Tighten:     tighten,
+		Backend:     DefaultLPBackend,
+		Exact:       true,
+		Objective:   ObjectiveNone,
 	}
 }
 
@@ -730,9 +997,34 @@ func NewLPSolver(tighten TightenMode) *LPSolver {
 // and tries to solve it.
 // It will also undo the renaming if required.
 func (s *LPSolver) Convert(phi br.ClauseSet, nbvar int) (*LPB, error) {
+	return s.ConvertContext(context.Background(), phi, nbvar)
+}
+
+// ConvertContext works as Convert but stops once ctx is done, see
+// LinearProgram.SolveContext.
+//
+// If ϕ is false 0 ⋅ x_1 + ... + 0 ⋅ x_n ≥ 1 is returned, if it is true
+// 0 ⋅ x_1 + ... + 0 ⋅ x_n ≥ 0.
+func (s *LPSolver) ConvertContext(ctx context.Context, phi br.ClauseSet, nbvar int) (*LPB, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	switch isFinal(phi) {
+	case IsFalse:
+		return NewLPB(1, make([]LPBCoeff, nbvar)), nil
+	case IsTrue:
+		return NewLPB(0, make([]LPBCoeff, nbvar)), nil
+	}
 	lp := NewLinearProgram(phi, nbvar, s.SortMatrix, s.SortClauses)
-	res, err := lp.Solve(s.Tighten, s.RegTest)
+	lp.Backend = s.Backend
+	lp.Exact = s.Exact
+	lp.Objective = s.Objective
+	lp.Tree.MaxNodes = s.MaxNodes
+	res, err := lp.SolveContext(ctx, s.Tighten, s.RegTest)
 	if err != nil {
+		if cert, ok := err.(*NotThresholdError); ok {
+			return nil, cert.Rename(lp.ReverseRenaming)
+		}
 		return nil, err
 	}
 	// undo renaming
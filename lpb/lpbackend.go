@@ -0,0 +1,156 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lpb
+
+import (
+	"math/big"
+	"time"
+)
+
+// ConstraintType describes the relation of a constraint in a linear program,
+// i.e. if the row must be ≤, ≥ or = the right hand side.
+type ConstraintType int
+
+const (
+	ConstraintLE ConstraintType = iota // row ≤ rhs
+	ConstraintGE                       // row ≥ rhs
+	ConstraintEQ                       // row = rhs
+)
+
+func (ct ConstraintType) String() string {
+	switch ct {
+	case ConstraintLE:
+		return "≤"
+	case ConstraintGE:
+		return "≥"
+	case ConstraintEQ:
+		return "="
+	default:
+		return "?"
+	}
+}
+
+// LPEntry is an entry in a sparse row of a linear program, it stores the
+// column (variable) and the value of the variable in the row.
+type LPEntry struct {
+	Col int
+	Val float64
+}
+
+// LPSolutionType describes the outcome of solving a linear program.
+type LPSolutionType int
+
+const (
+	LPOptimal    LPSolutionType = iota // An optimal solution was found
+	LPSuboptimal                       // A solution was found, but it might not be optimal
+	LPInfeasible                       // The problem has no solution
+	LPUnbounded                        // The objective function is unbounded
+	LPFailed                           // The solver failed for some other reason
+)
+
+func (t LPSolutionType) String() string {
+	switch t {
+	case LPOptimal:
+		return "optimal"
+	case LPSuboptimal:
+		return "suboptimal"
+	case LPInfeasible:
+		return "infeasible"
+	case LPUnbounded:
+		return "unbounded"
+	default:
+		return "failed"
+	}
+}
+
+// LPBackend is the interface for linear program solvers used by FormulateLP
+// and SolveLP.
+//
+// The interface is modeled after the golp bindings for lpsolve: All variables
+// (columns) are zero based and are assumed to be ≥ 0, the objective function
+// gets minimized.
+// Constraints are added one after another with AddConstraintSparse, after
+// calling Solve the values of the variables can be retrieved with Variables.
+//
+// There are two implementations: SimplexLP is written in pure Go and is
+// always available, the lpsolve backend is only available if the package is
+// built with the golp build tag (go build -tags golp) because it requires cgo
+// and an installed lpsolve library.
+type LPBackend interface {
+	// SetInt sets the integer constraint on the column.
+	SetInt(col int, mustBeInt bool)
+	// AddConstraintSparse adds the constraint row ct rhs.
+	AddConstraintSparse(row []LPEntry, ct ConstraintType, rhs float64) error
+	// SetObjFn sets the objective function, it must contain one entry for
+	// each column.
+	SetObjFn(row []float64)
+	// Solve solves the linear program.
+	Solve() LPSolutionType
+	// Variables returns the values of the variables after Solve was called.
+	Variables() []float64
+}
+
+// ExactLPBackend is an LPBackend that also provides the exact (rational)
+// values of the variables.
+//
+// SimplexLP implements this interface.
+type ExactLPBackend interface {
+	LPBackend
+	// RatVariables returns the values of the variables after Solve was called.
+	RatVariables() []*big.Rat
+}
+
+// FarkasLPBackend is an LPBackend that provides a certificate if the linear
+// program (without integer constraints) has no solution.
+//
+// FarkasDual returns nil if the relaxation is feasible. Otherwise it returns a
+// multiplier y(i) for each constraint (in the order they were added) with
+// y(i) ≥ 0 for ≥ constraints and y(i) ≤ 0 for ≤ constraints s.t. the sum of
+// y(i) ⋅ row(i) is ≤ 0 in each column and the sum of y(i) ⋅ rhs(i) is > 0.
+// Because all variables are ≥ 0 this proves that there is no solution
+// (Farkas' lemma).
+//
+// SimplexLP implements this interface.
+type FarkasLPBackend interface {
+	LPBackend
+	FarkasDual() []*big.Rat
+}
+
+// TimeoutLPBackend is an LPBackend that stops solving the linear program
+// after a timeout.
+//
+// If the timeout is reached before an optimal solution was found Solve
+// returns LPFailed or (if it already found a solution) LPSuboptimal.
+// A timeout ≤ 0 means that there is no timeout.
+//
+// SimplexLP and the lpsolve backend implement this interface.
+type TimeoutLPBackend interface {
+	LPBackend
+	SetTimeout(timeout time.Duration)
+}
+
+// LPBackendFactory creates a new backend for a linear program with the given
+// number of columns and no constraints.
+type LPBackendFactory func(numCols int) LPBackend
+
+// LPBackends contains all available LP backends by name.
+// "simplex" (NewSimplexLP) is always available, "golp" is available if built
+// with the golp build tag.
+var LPBackends = map[string]LPBackendFactory{
+	"simplex": NewSimplexLP,
+}
+
+// DefaultLPBackend is the backend used by NewLinearProgram and NewLPSolver.
+var DefaultLPBackend LPBackendFactory = NewSimplexLP
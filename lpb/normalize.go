@@ -0,0 +1,265 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lpb
+
+import (
+	"fmt"
+	"sort"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+// Normalize returns an LPB with small coefficients and threshold that
+// represents the same function.
+//
+// It does the following steps:
+// First coefficients greater than the threshold are set to the threshold,
+// they're true on their own anyway. Then all values are divided by their gcd.
+// Finally the coefficients and the threshold are lowered: For each coefficient
+// w(i) we compute the smallest value s.t. the largest weighted sum of a false
+// point is still smaller than the smallest weighted sum of a true point, the
+// threshold is set to the largest weighted sum of a false point + 1. This is
+// repeated until no coefficient changes. Then no single coefficient and not
+// the threshold can be lowered without changing the function.
+//
+// To lower a coefficient we compute all weighted sums of the other variables,
+// so this takes time and memory that is pseudo-polynomial in the coefficients
+// (and never more than 2^n). Note that the result is not unique: equivalent
+// LPBs might have different results, see CanonicalForm for this.
+//
+// The false function is normalized to 0 ⋅ x_1 + ... + 0 ⋅ x_n ≥ 1 and the
+// true function to 0 ⋅ x_1 + ... + 0 ⋅ x_n ≥ 0. Coefficients that are ∞ are
+// replaced by finite values. Negative coefficients (including -∞) are not
+// allowed, in this case an error is returned.
+func (lpb *LPB) Normalize() (*LPB, error) {
+	n := len(lpb.Coefficients)
+	for i, coeff := range lpb.Coefficients {
+		if coeff.Lesser(0) {
+			return nil, fmt.Errorf("Coefficient of x%d is %s, Normalize requires coefficients ≥ 0", i+1, coeff)
+		}
+	}
+	finite, err := lpb.finite()
+	if err != nil {
+		return nil, err
+	}
+	threshold := finite.Threshold
+	var sum LPBCoeff
+	for _, coeff := range finite.Coefficients {
+		sum += coeff
+	}
+	switch {
+	case threshold <= 0:
+		return NewLPB(0, make([]LPBCoeff, n)), nil
+	case sum < threshold:
+		return NewLPB(1, make([]LPBCoeff, n)), nil
+	}
+	coeffs := make([]LPBCoeff, n)
+	for i, coeff := range finite.Coefficients {
+		if coeff > threshold {
+			coeff = threshold
+		}
+		coeffs[i] = coeff
+	}
+	g := threshold
+	for _, coeff := range coeffs {
+		g = gcdCoeff(g, coeff)
+	}
+	for i := range coeffs {
+		coeffs[i] /= g
+	}
+	threshold /= g
+	for changed := true; changed; {
+		changed = false
+		for i, coeff := range coeffs {
+			newCoeff, newThreshold := lowerCoefficient(coeffs, threshold, i)
+			if newCoeff != coeff {
+				changed = true
+			}
+			coeffs[i], threshold = newCoeff, newThreshold
+		}
+	}
+	return NewLPB(threshold, coeffs), nil
+}
+
+// lowerCoefficient computes the smallest value of the coefficient of x_i that
+// represents the same function and the new threshold, see Normalize.
+//
+// Let S be the weighted sums of all subsets of the other variables. With w(i)
+// the largest false point without x_i has the sum B = max {s ∈ S | s < d},
+// the smallest true point with x_i has the sum A(i) + w(i) where
+// A(i) = min {s ∈ S | s ≥ d - w(i)} and the largest false point with x_i has
+// the sum B(i) + w(i) where B(i) = max {s ∈ S | s < d - w(i)}.
+// Because all other points don't change the function stays the same iff
+// A(i) + w'(i) > B. The new threshold is max(B, B(i) + w'(i)) + 1.
+// The function must be neither true nor false.
+func lowerCoefficient(coeffs []LPBCoeff, threshold LPBCoeff, i int) (LPBCoeff, LPBCoeff) {
+	sums := subsetSums(coeffs, i)
+	// index of the first sum ≥ x
+	firstGE := func(x LPBCoeff) int {
+		return sort.Search(len(sums), func(j int) bool { return sums[j] >= x })
+	}
+	rest := threshold - coeffs[i]
+	// the function is not true, so the empty set is a false point
+	b := sums[firstGE(threshold)-1]
+	// the function is not false, so the set of all variables is a true point
+	a := sums[firstGE(rest)]
+	newCoeff := b - a + 1
+	if newCoeff < 0 {
+		newCoeff = 0
+	}
+	newThreshold := b + 1
+	if j := firstGE(rest); j > 0 && sums[j-1]+newCoeff+1 > newThreshold {
+		newThreshold = sums[j-1] + newCoeff + 1
+	}
+	return newCoeff, newThreshold
+}
+
+// subsetSums returns the sorted weighted sums of all subsets of the variables
+// without x_skip, each sum is contained only once.
+func subsetSums(coeffs []LPBCoeff, skip int) []LPBCoeff {
+	sums := []LPBCoeff{0}
+	for i, coeff := range coeffs {
+		if i == skip || coeff == 0 {
+			continue
+		}
+		// merge sums and sums + coeff
+		merged := make([]LPBCoeff, 0, 2*len(sums))
+		j, k := 0, 0
+		for j < len(sums) || k < len(sums) {
+			var next LPBCoeff
+			if k == len(sums) || (j < len(sums) && sums[j] <= sums[k]+coeff) {
+				next = sums[j]
+				j++
+			} else {
+				next = sums[k] + coeff
+				k++
+			}
+			if len(merged) == 0 || merged[len(merged)-1] != next {
+				merged = append(merged, next)
+			}
+		}
+		sums = merged
+	}
+	return sums
+}
+
+// gcdCoeff returns the gcd of two values ≥ 0.
+func gcdCoeff(a, b LPBCoeff) LPBCoeff {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// CanonicalForm returns the canonical form of the LPB, an LPB that represents
+// the same function.
+//
+// The canonical form only depends on the function and not on the coefficients
+// of the LPB, so two LPBs represent the same function iff their canonical
+// forms are equal. Among all LPBs with integer values that represent the
+// function it is the one with the smallest sum of the threshold and all
+// coefficients. If there are several such LPBs the one with the smallest
+// coefficient of x1 is chosen, then the smallest coefficient of x2 and so on.
+//
+// This is much more expensive than Normalize: All minimal true points and
+// maximal false points of the LPB are computed and kept in memory (there
+// might be exponentially many), then a sequence of integer linear programs is
+// solved with SimplexLP: first the sum is minimized, then each coefficient one
+// after another.
+//
+// The false and true function are represented as in Normalize. If the LPB
+// can't be normalized or an lp can't be solved an error is returned.
+func (lpb *LPB) CanonicalForm() (*LPB, error) {
+	n := len(lpb.Coefficients)
+	normalized, err := lpb.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	var sum LPBCoeff
+	for _, coeff := range normalized.Coefficients {
+		sum += coeff
+	}
+	if sum == 0 {
+		// true or false
+		return normalized, nil
+	}
+	var mtps, mfps []*br.BitVector
+	normalized.StreamDNF(func(clause br.Clause) bool {
+		point := br.NewBitVector(n)
+		for _, v := range clause {
+			point.Set(v)
+		}
+		mtps = append(mtps, point)
+		return true
+	})
+	normalized.streamMFPs(func(point br.BooleanVector) bool {
+		mfps = append(mfps, br.NewBitVectorFrom(point))
+		return true
+	})
+	program, err := FormulateLP(mtps, mfps, n, nil, TightenNone, ObjectiveNone, NewSimplexLP, true)
+	if err != nil {
+		return nil, err
+	}
+	// minimize the threshold (column n) plus the sum of all coefficients
+	obj := make([]float64, n+1)
+	all := make([]LPEntry, n+1)
+	for i := range obj {
+		obj[i] = 1
+		all[i] = LPEntry{Col: i, Val: 1}
+	}
+	program.SetObjFn(obj)
+	res, err := SolveLP(program, n)
+	if err != nil {
+		return nil, err
+	}
+	total := res.Threshold
+	for _, coeff := range res.Coefficients {
+		total += coeff
+	}
+	if err := program.AddConstraintSparse(all, ConstraintEQ, float64(total)); err != nil {
+		return nil, err
+	}
+	// fix the coefficients one after another to their minimal value
+	for i := 0; i < n; i++ {
+		for j := range obj {
+			obj[j] = 0
+		}
+		obj[i] = 1
+		program.SetObjFn(obj)
+		if res, err = SolveLP(program, n); err != nil {
+			return nil, err
+		}
+		if err := program.AddConstraintSparse([]LPEntry{{Col: i, Val: 1}}, ConstraintEQ, float64(res.Coefficients[i])); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// EquivalentTo checks if two LPBs represent the same function.
+//
+// It computes the minimal true points of the LPB (see ToDNF) and then checks
+// with Verify that other has the same minimal true points and maximal false
+// points. Neither LPB has to be sorted.
+// Both LPBs must have the same number of variables, otherwise false is
+// returned.
+// Computing the DNF might take some time, for a pseudo-polynomial test see
+// ChowEquals.
+func (lpb *LPB) EquivalentTo(other *LPB) bool {
+	if len(lpb.Coefficients) != len(other.Coefficients) {
+		return false
+	}
+	return Verify(other, lpb.ToDNF(), len(lpb.Coefficients)) == nil
+}
@@ -207,29 +207,15 @@ func SortAll(patterns []*OccurrencePattern) {
 
 // ComputeMaxL computes the max l s.t. the first l patterns are equal.
 //
-// There are some TODO here.
+// If there are no patterns it returns 0.
 func ComputeMaxL(patterns []*OccurrencePattern) int {
+	if len(patterns) == 0 {
+		return 0
+	}
 	l := 1
-	// TODO: Strange behavior if the DNF cannot be represented as a LPB
-	// first can be null in this case?
-	// why? JGS: let's see if implementing a symmetry test will remedy this.
 	first := patterns[0]
-	for l < len(patterns) {
-		next := patterns[l]
-		if next == nil {
-			// TODO create new empty OP here, correct?
-			next = EmptyOccurrencePattern(10)
-			patterns[l] = next
-		}
-		if first == nil { // TODO JGS: to fix the segmentation fault
-			l++
-		} else {
-			if first.CompareTo(next) == 0 {
-				l++
-			} else {
-				break
-			}
-		}
+	for l < len(patterns) && first.CompareTo(patterns[l]) == 0 {
+		l++
 	}
 	return l
 }
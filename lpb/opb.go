@@ -0,0 +1,244 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lpb
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// This file contains a reader and writer for the OPB format used in the
+// pseudo-Boolean competitions.
+//
+// An OPB file contains constraints of the form
+//
+//	+2 x1 +1 x2 >= 2 ;
+//
+// Each constraint ends with a semicolon and may span several lines, lines
+// that start with * are comments. The first line usually is a comment of the
+// form "* #variable= 2 #constraint= 1".
+// An objective function ("min: +1 x1 ;") is allowed but ignored.
+
+// opbStandardName matches variable names of the form x1, x2, ...
+var opbStandardName = regexp.MustCompile(`^x([1-9][0-9]*)$`)
+
+// opbHeader matches the number of variables in the header comment.
+var opbHeader = regexp.MustCompile(`#variable=\s*([0-9]+)`)
+
+// opbTerm is a term a ⋅ x in an OPB constraint.
+type opbTerm struct {
+	coeff int
+	name  string
+}
+
+// opbConstraint is a constraint as it was read from the file.
+type opbConstraint struct {
+	terms     []opbTerm
+	threshold int
+}
+
+// ParseOPB reads all constraints from an OPB file and returns them as LPBs.
+//
+// The second return value contains the names of the variables: The variable
+// with index i in the LPBs has the name names[i]. If all names are of the
+// form x<k> (as in the competition format) the variable x<k> gets index
+// k - 1, otherwise the variables are numbered in the order of their first
+// occurrence. All LPBs have the same number of variables, this is the number
+// of names or the value of #variable= in the header if it is greater.
+//
+// Only constraints that can be represented as an LPB are supported: The
+// relation must be >= and all coefficients must be ≥ 0, negated literals
+// (~x) and products of literals are not allowed. A threshold < 0 is replaced
+// by 0 (the constraint is always true). If a variable occurs more than once
+// in a constraint the coefficients are added.
+func ParseOPB(r io.Reader) ([]*LPB, []string, error) {
+	scanner := bufio.NewScanner(r)
+	var constraints []*opbConstraint
+	headerVars := 0
+	// the tokens of the current statement
+	var tokens []string
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "*") {
+			if match := opbHeader.FindStringSubmatch(line); match != nil {
+				headerVars, _ = strconv.Atoi(match[1])
+			}
+			continue
+		}
+		line = strings.Replace(line, ";", " ; ", -1)
+		for _, token := range strings.Fields(line) {
+			if token != ";" {
+				tokens = append(tokens, token)
+				continue
+			}
+			c, err := parseOPBStatement(tokens)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Error in line %d: %s", lineNum, err.Error())
+			}
+			if c != nil {
+				constraints = append(constraints, c)
+			}
+			tokens = tokens[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) != 0 {
+		return nil, nil, fmt.Errorf("Error in line %d: missing ; at the end of the constraint", lineNum)
+	}
+	// assign the indices
+	indices := make(map[string]int)
+	var names []string
+	standard := true
+	for _, c := range constraints {
+		for _, term := range c.terms {
+			if !opbStandardName.MatchString(term.name) {
+				standard = false
+			}
+			if _, has := indices[term.name]; !has {
+				indices[term.name] = len(names)
+				names = append(names, term.name)
+			}
+		}
+	}
+	if standard {
+		nbvar := 0
+		for name := range indices {
+			k, _ := strconv.Atoi(name[1:])
+			indices[name] = k - 1
+			if k > nbvar {
+				nbvar = k
+			}
+		}
+		names = make([]string, nbvar)
+		for i := range names {
+			names[i] = "x" + strconv.Itoa(i+1)
+		}
+	}
+	for len(names) < headerVars {
+		names = append(names, "x"+strconv.Itoa(len(names)+1))
+	}
+	res := make([]*LPB, len(constraints))
+	for i, c := range constraints {
+		coeffs := make([]LPBCoeff, len(names))
+		for _, term := range c.terms {
+			coeffs[indices[term.name]] += LPBCoeff(term.coeff)
+		}
+		res[i] = NewLPB(LPBCoeff(c.threshold), coeffs)
+	}
+	return res, names, nil
+}
+
+// parseOPBStatement parses the tokens of a statement (without the
+// semicolon). It returns nil for objective functions.
+func parseOPBStatement(tokens []string) (*opbConstraint, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("Empty constraint")
+	}
+	if tokens[0] == "min:" || tokens[0] == "max:" {
+		return nil, nil
+	}
+	if len(tokens) < 2 {
+		return nil, fmt.Errorf("Invalid constraint \"%s\"", strings.Join(tokens, " "))
+	}
+	relation, rhs := tokens[len(tokens)-2], tokens[len(tokens)-1]
+	if relation != ">=" {
+		return nil, fmt.Errorf("Only >= constraints are supported, got \"%s\"", relation)
+	}
+	threshold, err := strconv.Atoi(rhs)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid threshold \"%s\"", rhs)
+	}
+	if threshold < 0 {
+		threshold = 0
+	}
+	terms := tokens[:len(tokens)-2]
+	if len(terms)%2 != 0 {
+		return nil, errors.New("Each term must consist of a coefficient and a variable")
+	}
+	res := &opbConstraint{terms: make([]opbTerm, 0, len(terms)/2), threshold: threshold}
+	for i := 0; i < len(terms); i += 2 {
+		coeff, err := strconv.Atoi(terms[i])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid coefficient \"%s\"", terms[i])
+		}
+		if coeff < 0 {
+			return nil, fmt.Errorf("LPB coefficients must be positive, got %d", coeff)
+		}
+		name := terms[i+1]
+		if strings.HasPrefix(name, "~") {
+			return nil, fmt.Errorf("Negated literals are not supported, got \"%s\"", name)
+		}
+		if _, err := strconv.Atoi(name); err == nil {
+			return nil, fmt.Errorf("Expected variable, got \"%s\"", name)
+		}
+		res.terms = append(res.terms, opbTerm{coeff: coeff, name: name})
+	}
+	return res, nil
+}
+
+// WriteOPB writes the LPBs in OPB format to the writer.
+//
+// names contains the names of the variables (see ParseOPB), if it is nil the
+// variables are named x1, x2, .... Coefficients that are 0 are omitted (if
+// all coefficients are 0 the first one is written). Coefficients and
+// thresholds that are ∞ or -∞ can't be written.
+func WriteOPB(w io.Writer, lpbs []*LPB, names []string) error {
+	nbvar := len(names)
+	for _, lpb := range lpbs {
+		if len(lpb.Coefficients) > nbvar {
+			nbvar = len(lpb.Coefficients)
+		}
+	}
+	if names == nil {
+		names = make([]string, nbvar)
+		for i := range names {
+			names[i] = "x" + strconv.Itoa(i+1)
+		}
+	} else if len(names) < nbvar {
+		return fmt.Errorf("Got %d names for %d variables", len(names), nbvar)
+	}
+	buffer := bufio.NewWriter(w)
+	fmt.Fprintf(buffer, "* #variable= %d #constraint= %d\n", nbvar, len(lpbs))
+	for _, lpb := range lpbs {
+		if lpb.Threshold == PositiveInfinity || lpb.Threshold == NegativeInfinity {
+			return fmt.Errorf("Can't write threshold %s in OPB format", lpb.Threshold)
+		}
+		written := false
+		for i, coeff := range lpb.Coefficients {
+			if coeff == PositiveInfinity || coeff == NegativeInfinity {
+				return fmt.Errorf("Can't write coefficient %s in OPB format", coeff)
+			}
+			if coeff == 0 {
+				continue
+			}
+			fmt.Fprintf(buffer, "+%d %s ", coeff, names[i])
+			written = true
+		}
+		if !written && len(lpb.Coefficients) > 0 {
+			fmt.Fprintf(buffer, "+0 %s ", names[0])
+		}
+		fmt.Fprintf(buffer, ">= %d ;\n", lpb.Threshold)
+	}
+	return buffer.Flush()
+}
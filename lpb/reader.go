@@ -0,0 +1,157 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lpb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode"
+)
+
+// SyntaxError is returned by ParseLPB and LPBReader if the input is not a
+// valid LPB. Line and Column start with 1, the column is the position of the
+// first character (rune) of the invalid token.
+type SyntaxError struct {
+	Line, Column int
+	Msg          string
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("Syntax error in line %d, column %d: %s", err.Line, err.Column, err.Msg)
+}
+
+// lpbToken is a token in an LPB line with its position (starting with 1).
+type lpbToken struct {
+	value  string
+	column int
+}
+
+// tokenizeLPB splits a line into tokens separated by whitespace, everything
+// after a # is ignored. Lines that start with the token "c" are comments and
+// contain no tokens.
+func tokenizeLPB(line string) []lpbToken {
+	var res []lpbToken
+	start := -1
+	column := 0
+	var current []rune
+	for _, r := range line {
+		column++
+		if r == '#' {
+			break
+		}
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				res = append(res, lpbToken{string(current), start})
+				start = -1
+				current = current[:0]
+			}
+			continue
+		}
+		if start < 0 {
+			start = column
+		}
+		current = append(current, r)
+	}
+	if start >= 0 {
+		res = append(res, lpbToken{string(current), start})
+	}
+	if len(res) > 0 && res[0].value == "c" {
+		return nil
+	}
+	return res
+}
+
+// parseLPBTokens parses an LPB from the tokens of a line, line is only used
+// for errors.
+func parseLPBTokens(tokens []lpbToken, line int) (*LPB, error) {
+	if len(tokens) == 0 {
+		return nil, &SyntaxError{Line: line, Column: 1, Msg: "LPB description is empty"}
+	}
+	values := make([]int, len(tokens))
+	for i, token := range tokens {
+		val, err := strconv.Atoi(token.value)
+		if err != nil {
+			return nil, &SyntaxError{Line: line, Column: token.column,
+				Msg: fmt.Sprintf("expected integer, got \"%s\"", token.value)}
+		}
+		if val < 0 {
+			if i == len(tokens)-1 {
+				return nil, &SyntaxError{Line: line, Column: token.column,
+					Msg: fmt.Sprintf("LPB threshold must not be negative, got %d", val)}
+			}
+			return nil, &SyntaxError{Line: line, Column: token.column,
+				Msg: fmt.Sprintf("LPB coefficients must be positive, got %d", val)}
+		}
+		values[i] = val
+	}
+	coefficients := make([]LPBCoeff, len(values)-1)
+	for i, val := range values[:len(values)-1] {
+		coefficients[i] = LPBCoeff(val)
+	}
+	return NewLPB(LPBCoeff(values[len(values)-1]), coefficients), nil
+}
+
+// LPBReader reads LPBs line by line, the format of each line is described in
+// ParseLPB. Empty lines and comments are ignored.
+type LPBReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewLPBReader returns a new reader that reads from r.
+func NewLPBReader(r io.Reader) *LPBReader {
+	return &LPBReader{scanner: bufio.NewScanner(r)}
+}
+
+// Next returns the next LPB. At the end of the input it returns io.EOF.
+// If a line is not a valid LPB the error is a *SyntaxError.
+func (r *LPBReader) Next() (*LPB, error) {
+	for r.scanner.Scan() {
+		r.line++
+		tokens := tokenizeLPB(r.scanner.Text())
+		if len(tokens) == 0 {
+			continue
+		}
+		return parseLPBTokens(tokens, r.line)
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Line returns the line of the LPB returned by the last call of Next.
+func (r *LPBReader) Line() int {
+	return r.line
+}
+
+// ReadLPBs reads all LPBs from r, see LPBReader. To process the LPBs one
+// after another without storing them use LPBReader directly.
+func ReadLPBs(r io.Reader) ([]*LPB, error) {
+	reader := NewLPBReader(r)
+	var res []*LPB
+	for {
+		lpb, err := reader.Next()
+		switch {
+		case err == io.EOF:
+			return res, nil
+		case err != nil:
+			return nil, err
+		}
+		res = append(res, lpb)
+	}
+}
@@ -0,0 +1,325 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lpb
+
+import (
+	"bytes"
+	"fmt"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+// SignedLPB represents an LPB of the form a_1 ⋅ x_1 + ... + a_n ⋅ x_n ≥ d
+// where the coefficients and the threshold can be any integers.
+//
+// LPB reserves negative values for ∞ and -∞, so we need another type for
+// functions that are not monotone.
+type SignedLPB struct {
+	Threshold    int
+	Coefficients []int
+}
+
+// NewSignedLPB creates a new signed LPB with the given threshold and
+// coefficients.
+func NewSignedLPB(threshold int, coefficients []int) *SignedLPB {
+	return &SignedLPB{Threshold: threshold, Coefficients: coefficients}
+}
+
+func (lpb *SignedLPB) String() string {
+	buffer := new(bytes.Buffer)
+	switch len(lpb.Coefficients) {
+	case 0:
+		buffer.WriteRune('0')
+	default:
+		fmt.Fprintf(buffer, "%d⋅x1", lpb.Coefficients[0])
+		for i, c := range lpb.Coefficients[1:] {
+			if c < 0 {
+				fmt.Fprintf(buffer, " - %d⋅x%d", -c, i+2)
+			} else {
+				fmt.Fprintf(buffer, " + %d⋅x%d", c, i+2)
+			}
+		}
+	}
+	fmt.Fprintf(buffer, " ≥ %d", lpb.Threshold)
+	return buffer.String()
+}
+
+// Equals checks if to signed LPBs are syntactically equal.
+func (lpb *SignedLPB) Equals(other *SignedLPB) bool {
+	if lpb.Threshold != other.Threshold || len(lpb.Coefficients) != len(other.Coefficients) {
+		return false
+	}
+	for i, val := range lpb.Coefficients {
+		if val != other.Coefficients[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Unateness describes how a variable occurs in a DNF.
+type Unateness int
+
+const (
+	Unused        Unateness = iota // The variable does not occur in the DNF
+	PositiveUnate                  // The variable occurs only positive
+	NegativeUnate                  // The variable occurs only negated
+	Binate                         // The variable occurs positive and negated
+)
+
+func (u Unateness) String() string {
+	switch u {
+	case Unused:
+		return "unused"
+	case PositiveUnate:
+		return "positive unate"
+	case NegativeUnate:
+		return "negative unate"
+	default:
+		return "binate"
+	}
+}
+
+// BinateError is returned if a variable occurs positive and negated in the
+// prime implicants of a DNF, such a DNF can't be transformed to a positive
+// DNF.
+// Variable is the id of the variable (starting with 0).
+type BinateError struct {
+	Variable int
+}
+
+func (err *BinateError) Error() string {
+	return fmt.Sprintf("Variable x%d occurs positive and negated, DNF is not unate", err.Variable+1)
+}
+
+// VariableUnateness computes the unateness of each variable in a general DNF.
+//
+// In a general DNF a literal is a number ≠ 0: the variable with id i (starting
+// with 0) is represented by i + 1, its negation by -(i + 1). This is the same
+// as in the DIMACS format.
+//
+// The test is semantic: ϕ is first replaced by its prime implicants (see
+// ClauseSet.PrimeImplicants), a function is unate in a variable iff the
+// variable occurs only positive or only negated in the prime implicants.
+// For example x1 ∨ x2 ∨ x1 ¬x2 is the same as x1 ∨ x2 and both variables are
+// positive unate. Variables that only occur in redundant clauses are Unused.
+func VariableUnateness(phi br.ClauseSet, nbvar int) ([]Unateness, error) {
+	if err := checkLiterals(phi, nbvar); err != nil {
+		return nil, err
+	}
+	return primeUnateness(phi.PrimeImplicants(), nbvar), nil
+}
+
+// checkLiterals checks if all literals in ϕ are valid literals of a general
+// DNF with nbvar variables.
+func checkLiterals(phi br.ClauseSet, nbvar int) error {
+	for _, clause := range phi {
+		for _, literal := range clause {
+			if _, _, err := decodeLiteral(literal, nbvar); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// primeUnateness computes the unateness of each variable in the prime
+// implicants of a general DNF, all literals must be valid.
+func primeUnateness(primes br.ClauseSet, nbvar int) []Unateness {
+	res := make([]Unateness, nbvar)
+	for _, clause := range primes {
+		for _, literal := range clause {
+			v, negated, _ := decodeLiteral(literal, nbvar)
+			occurrence := PositiveUnate
+			if negated {
+				occurrence = NegativeUnate
+			}
+			switch res[v] {
+			case Unused:
+				res[v] = occurrence
+			case occurrence, Binate:
+			default:
+				res[v] = Binate
+			}
+		}
+	}
+	return res
+}
+
+// ToPositive transforms a general unate DNF (see VariableUnateness) to a
+// positive DNF by replacing each negative unate variable x by ¬x.
+//
+// It returns the positive DNF (variables start with 0 as usual) and for each
+// variable the information if it was negated. If a variable is binate a
+// *BinateError is returned.
+//
+// The positive DNF is computed from the prime implicants of ϕ, thus the
+// result is a minimal positive DNF.
+func ToPositive(phi br.ClauseSet, nbvar int) (br.ClauseSet, []bool, error) {
+	if err := checkLiterals(phi, nbvar); err != nil {
+		return nil, nil, err
+	}
+	primes := phi.PrimeImplicants()
+	negated := make([]bool, nbvar)
+	for v, u := range primeUnateness(primes, nbvar) {
+		switch u {
+		case Binate:
+			return nil, nil, &BinateError{Variable: v}
+		case NegativeUnate:
+			negated[v] = true
+		}
+	}
+	positive := br.NewClauseSet(len(primes))
+	for _, clause := range primes {
+		newClause := br.NewClause(len(clause))
+		for _, literal := range clause {
+			v, _, _ := decodeLiteral(literal, nbvar)
+			newClause = append(newClause, v)
+		}
+		positive = append(positive, newClause)
+	}
+	return positive.Minimize(), negated, nil
+}
+
+// SignedConverter converts general DNFs to signed LPBs.
+//
+// The DNF must be unate, it gets transformed to a positive DNF with
+// ToPositive. This DNF is converted by Converter and then the negated
+// variables in the LPB get replaced again: a ⋅ ¬x = a - a ⋅ x, so the
+// coefficient of x becomes -a and a gets subtracted from the threshold.
+//
+// Variables that don't occur in the DNF get coefficient 0, they're removed
+// before calling Converter.
+// If the converter returns a *NotThresholdError the certificate is for
+// the positive DNF returned by ToPositive.
+type SignedConverter struct {
+	Converter DNFToLPB
+}
+
+// NewSignedConverter returns a new signed converter that uses converter to
+// convert the positive DNF.
+func NewSignedConverter(converter DNFToLPB) *SignedConverter {
+	return &SignedConverter{Converter: converter}
+}
+
+// Convert converts the general DNF ϕ to a signed LPB, for the
+// representation of ϕ see VariableUnateness.
+func (c *SignedConverter) Convert(phi br.ClauseSet, nbvar int) (*SignedLPB, error) {
+	positive, negated, err := ToPositive(phi, nbvar)
+	if err != nil {
+		return nil, err
+	}
+	coefficients := make([]int, nbvar)
+	switch isFinal(positive) {
+	case IsFalse:
+		return NewSignedLPB(1, coefficients), nil
+	case IsTrue:
+		return NewSignedLPB(0, coefficients), nil
+	}
+	// remove the variables that don't occur
+	compact, ids := compactDNF(positive, nbvar)
+	res, err := c.Converter.Convert(compact, len(ids))
+	if err != nil {
+		if cert, ok := err.(*NotThresholdError); ok {
+			return nil, cert.expand(ids, nbvar)
+		}
+		return nil, err
+	}
+	threshold := int(res.Threshold)
+	for i, v := range ids {
+		coeff := int(res.Coefficients[i])
+		if negated[v] {
+			coefficients[v] = -coeff
+			threshold -= coeff
+		} else {
+			coefficients[v] = coeff
+		}
+	}
+	return NewSignedLPB(threshold, coefficients), nil
+}
+
+// decodeLiteral returns the variable (starting with 0) of a literal in a
+// general DNF and if it is negated.
+func decodeLiteral(literal, nbvar int) (int, bool, error) {
+	negated := literal < 0
+	if negated {
+		literal = -literal
+	}
+	if literal == 0 || literal > nbvar {
+		return -1, false, fmt.Errorf("Invalid literal %d, must be in the range 1 ≤ |l| ≤ %d", literal, nbvar)
+	}
+	return literal - 1, negated, nil
+}
+
+// compactDNF renames the variables in a positive DNF s.t. only variables that
+// occur in ϕ are used. It returns the new DNF and for each new variable the
+// old id.
+func compactDNF(phi br.ClauseSet, nbvar int) (br.ClauseSet, []int) {
+	newIDs := make([]int, nbvar)
+	for i := range newIDs {
+		newIDs[i] = -1
+	}
+	for _, clause := range phi {
+		for _, v := range clause {
+			newIDs[v] = 0
+		}
+	}
+	ids := make([]int, 0, nbvar)
+	for v, id := range newIDs {
+		if id == 0 {
+			newIDs[v] = len(ids)
+			ids = append(ids, v)
+		}
+	}
+	res := br.NewClauseSet(len(phi))
+	for _, clause := range phi {
+		newClause := br.NewClause(len(clause))
+		for _, v := range clause {
+			newClause = append(newClause, newIDs[v])
+		}
+		res = append(res, newClause)
+	}
+	return res, ids
+}
+
+// expand transforms a certificate for a DNF created by compactDNF to a
+// certificate for the original DNF, unused variables are set to 0 in all
+// points.
+func (err *NotThresholdError) expand(ids []int, nbvar int) *NotThresholdError {
+	expandPoint := func(point br.BooleanVector) br.BooleanVector {
+		res := br.NewBooleanVector(nbvar)
+		for i, val := range point {
+			res[ids[i]] = val
+		}
+		return res
+	}
+	res := &NotThresholdError{}
+	if err.Regularity != nil {
+		res.Regularity = &RegularityCertificate{MTP: expandPoint(err.Regularity.MTP),
+			First:  ids[err.Regularity.First],
+			Second: ids[err.Regularity.Second],
+		}
+	}
+	if err.Asummability != nil {
+		res.Asummability = &AsummabilityWitness{}
+		for _, point := range err.Asummability.TruePoints {
+			res.Asummability.TruePoints = append(res.Asummability.TruePoints, expandPoint(point))
+		}
+		for _, point := range err.Asummability.FalsePoints {
+			res.Asummability.FalsePoints = append(res.Asummability.FalsePoints, expandPoint(point))
+		}
+	}
+	return res
+}
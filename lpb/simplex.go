@@ -0,0 +1,705 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lpb
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// ratEntry is an entry in a sparse row with an exact value.
+type ratEntry struct {
+	col int
+	val *big.Rat
+}
+
+// simplexConstraint is a constraint row ct rhs.
+type simplexConstraint struct {
+	row []ratEntry
+	ct  ConstraintType
+	rhs *big.Rat
+}
+
+// SimplexLP implements ExactLPBackend and FarkasLPBackend in pure Go, so no
+// cgo or lpsolve is required.
+//
+// All computations are done with rational numbers (math/big.Rat), so there
+// are no rounding errors. The linear program relaxation is solved with the
+// two phase simplex method (using Bland's rule to avoid cycling) and
+// constraint generation (see solveRelaxation), integer constraints are handled
+// with a depth first branch and bound.
+//
+// This is of course not as fast as lpsolve on big programs, but the linear
+// programs we create have small coefficients and are usually not that big.
+//
+// The timeout (see TimeoutLPBackend) is checked before each relaxation in the
+// branch and bound and in each step of the constraint generation.
+type SimplexLP struct {
+	numCols     int
+	constraints []simplexConstraint
+	isInt       []bool
+	obj         []*big.Rat
+	solution    []*big.Rat
+	farkas      []*big.Rat
+	timeout     time.Duration
+	deadline    time.Time
+}
+
+// NewSimplexLP returns a new SimplexLP with numCols columns, no constraints
+// and the objective function set to 0.
+func NewSimplexLP(numCols int) LPBackend {
+	obj := make([]*big.Rat, numCols)
+	for i := range obj {
+		obj[i] = new(big.Rat)
+	}
+	return &SimplexLP{numCols: numCols,
+		constraints: nil,
+		isInt:       make([]bool, numCols),
+		obj:         obj,
+		solution:    nil,
+		farkas:      nil,
+	}
+}
+
+// SetInt sets the integer constraint on the column.
+func (lp *SimplexLP) SetInt(col int, mustBeInt bool) {
+	lp.isInt[col] = mustBeInt
+}
+
+// AddConstraintSparse adds the constraint row ct rhs. All columns must be
+// valid and all values must be finite.
+func (lp *SimplexLP) AddConstraintSparse(row []LPEntry, ct ConstraintType, rhs float64) error {
+	ratRHS := new(big.Rat)
+	if ratRHS.SetFloat64(rhs) == nil {
+		return fmt.Errorf("Invalid right hand side %v in constraint", rhs)
+	}
+	ratRow := make([]ratEntry, 0, len(row))
+	for _, entry := range row {
+		if entry.Col < 0 || entry.Col >= lp.numCols {
+			return fmt.Errorf("Invalid column %d, must be between 0 and %d", entry.Col, lp.numCols-1)
+		}
+		val := new(big.Rat)
+		if val.SetFloat64(entry.Val) == nil {
+			return fmt.Errorf("Invalid value %v in constraint", entry.Val)
+		}
+		ratRow = append(ratRow, ratEntry{entry.Col, val})
+	}
+	lp.constraints = append(lp.constraints, simplexConstraint{ratRow, ct, ratRHS})
+	return nil
+}
+
+// SetObjFn sets the objective function that gets minimized.
+func (lp *SimplexLP) SetObjFn(row []float64) {
+	for i := 0; i < lp.numCols; i++ {
+		lp.obj[i] = new(big.Rat)
+		if i < len(row) {
+			lp.obj[i].SetFloat64(row[i])
+		}
+	}
+}
+
+// SetTimeout sets the timeout for Solve, see TimeoutLPBackend.
+func (lp *SimplexLP) SetTimeout(timeout time.Duration) {
+	lp.timeout = timeout
+}
+
+// timedOut checks if the deadline of the current call to Solve has passed.
+func (lp *SimplexLP) timedOut() bool {
+	return !lp.deadline.IsZero() && time.Now().After(lp.deadline)
+}
+
+// Solve solves the linear program with branch and bound, each relaxation is
+// solved with the simplex method.
+func (lp *SimplexLP) Solve() LPSolutionType {
+	lp.solution = nil
+	lp.farkas = nil
+	lp.deadline = time.Time{}
+	if lp.timeout > 0 {
+		lp.deadline = time.Now().Add(lp.timeout)
+	}
+	var best []*big.Rat
+	var bestObj *big.Rat
+	// if all columns in the objective are integers with integer coefficients
+	// the objective value of each integer solution is an integer as well
+	// and we can prune more nodes
+	intObj := lp.hasIntegerObjective()
+	// each entry on the stack is a list of additional bounds on the columns
+	stack := [][]simplexConstraint{nil}
+	for len(stack) > 0 {
+		if lp.timedOut() {
+			if best == nil {
+				return LPFailed
+			}
+			lp.solution = best
+			return LPSuboptimal
+		}
+		bounds := stack[len(stack)-1]
+		stack[len(stack)-1] = nil
+		stack = stack[:len(stack)-1]
+		res := lp.solveRelaxation(bounds)
+		switch res.status {
+		case LPInfeasible:
+			if bounds == nil {
+				// the relaxation itself is infeasible, keep the certificate
+				lp.farkas = res.farkas[:len(lp.constraints)]
+			}
+			continue
+		case LPUnbounded, LPFailed:
+			if res.status == LPFailed && best != nil && lp.timedOut() {
+				lp.solution = best
+				return LPSuboptimal
+			}
+			return res.status
+		}
+		values, objVal := res.values, res.objVal
+		if best != nil {
+			bound := objVal
+			if intObj {
+				bound = ratCeil(objVal)
+			}
+			if bound.Cmp(bestObj) >= 0 {
+				continue
+			}
+		}
+		// find a column that should be an integer, but is not
+		branchCol := -1
+		for col, mustBeInt := range lp.isInt {
+			if mustBeInt && !values[col].IsInt() {
+				branchCol = col
+				break
+			}
+		}
+		if branchCol < 0 {
+			best, bestObj = values, objVal
+			continue
+		}
+		floor := ratFloor(values[branchCol])
+		ceil := new(big.Rat).Add(floor, big.NewRat(1, 1))
+		entry := []ratEntry{{branchCol, big.NewRat(1, 1)}}
+		// the ≤ branch is pushed first, so the ≥ branch is explored first:
+		// scaling up a solution is usually possible in our programs
+		le := make([]simplexConstraint, len(bounds), len(bounds)+1)
+		copy(le, bounds)
+		le = append(le, simplexConstraint{entry, ConstraintLE, floor})
+		ge := make([]simplexConstraint, len(bounds), len(bounds)+1)
+		copy(ge, bounds)
+		ge = append(ge, simplexConstraint{entry, ConstraintGE, ceil})
+		stack = append(stack, le, ge)
+	}
+	if best == nil {
+		return LPInfeasible
+	}
+	lp.solution = best
+	return LPOptimal
+}
+
+// Variables returns the values of all columns, it returns nil if Solve was
+// not called or no solution was found.
+func (lp *SimplexLP) Variables() []float64 {
+	if lp.solution == nil {
+		return nil
+	}
+	res := make([]float64, len(lp.solution))
+	for i, val := range lp.solution {
+		res[i], _ = val.Float64()
+	}
+	return res
+}
+
+// RatVariables returns the exact values of all columns, it returns nil if
+// Solve was not called or no solution was found.
+func (lp *SimplexLP) RatVariables() []*big.Rat {
+	if lp.solution == nil {
+		return nil
+	}
+	res := make([]*big.Rat, len(lp.solution))
+	for i, val := range lp.solution {
+		res[i] = new(big.Rat).Set(val)
+	}
+	return res
+}
+
+// FarkasDual returns the Farkas certificate if the relaxation of the program
+// is infeasible, otherwise it returns nil. See FarkasLPBackend for details.
+func (lp *SimplexLP) FarkasDual() []*big.Rat {
+	return lp.farkas
+}
+
+func (lp *SimplexLP) hasIntegerObjective() bool {
+	for col, val := range lp.obj {
+		if val.Sign() != 0 && !(lp.isInt[col] && val.IsInt()) {
+			return false
+		}
+	}
+	return true
+}
+
+// ratFloor returns ⌊r⌋.
+func ratFloor(r *big.Rat) *big.Rat {
+	// Quo truncates towards zero, so we have to correct negative values
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if m.Sign() < 0 {
+		q.Sub(q, big.NewInt(1))
+	}
+	return new(big.Rat).SetInt(q)
+}
+
+// ratCeil returns ⌈r⌉.
+func ratCeil(r *big.Rat) *big.Rat {
+	res := ratFloor(r)
+	if !r.IsInt() {
+		res.Add(res, big.NewRat(1, 1))
+	}
+	return res
+}
+
+// simplexTableau is the tableau used to solve the relaxation.
+// Each row stores the coefficients of the columns and the right hand side
+// in the last position, nil entries are zero.
+//
+// The columns are the original columns followed by one slack / surplus column
+// for each inequality. Artificial variables are not stored: once they leave
+// the basis they never enter it again.
+// basis stores for each row the basic column or -1 if the row still has its
+// artificial variable in the basis.
+type simplexTableau struct {
+	rows  [][]*big.Rat
+	cost  []*big.Rat
+	basis []int
+	// numCols is the number of columns without the rhs
+	numCols int
+	// slacks stores for each constraint the slack columns of its rows,
+	// see farkasDual
+	slacks [][]slackInfo
+}
+
+// slackInfo stores the slack column of a row and the factor (sign ⋅ s) the
+// reduced cost must be multiplied with in farkasDual.
+type slackInfo struct {
+	col, factor int
+}
+
+// relaxation is the result of solving a relaxation.
+// values and objVal are set if status is LPOptimal, farkas is set if status
+// is LPInfeasible (see farkasDual).
+type relaxation struct {
+	status         LPSolutionType
+	values, farkas []*big.Rat
+	objVal         *big.Rat
+}
+
+// solveRelaxation solves the linear program without integer constraints,
+// bounds are added as additional constraints.
+//
+// The programs we create have only a few columns, but lots of rows (one for
+// each minimal true point and maximal false point). Solving them with one big
+// tableau is really slow, so we use constraint generation: We start with the
+// bounds and the equality constraints, solve this smaller program and then add
+// the constraints that are violated the most by this solution.
+// We repeat that until all constraints are satisfied.
+func (lp *SimplexLP) solveRelaxation(bounds []simplexConstraint) relaxation {
+	all := make([]simplexConstraint, 0, len(lp.constraints)+len(bounds))
+	all = append(all, lp.constraints...)
+	all = append(all, bounds...)
+	active := make([]simplexConstraint, 0, len(all))
+	// the index of each active constraint in all
+	activeIndex := make([]int, 0, len(all))
+	isActive := make([]bool, len(all))
+	for i, c := range all {
+		if c.ct == ConstraintEQ || i >= len(lp.constraints) {
+			active = append(active, c)
+			activeIndex = append(activeIndex, i)
+			isActive[i] = true
+		}
+	}
+	// the number of constraints we add in each step
+	batchSize := 2 * (lp.numCols + 1)
+	for {
+		if lp.timedOut() {
+			return relaxation{status: LPFailed}
+		}
+		res := solveTableau(active, lp.numCols, lp.obj)
+		switch res.status {
+		case LPInfeasible:
+			// the certificate for the active constraints is also a certificate
+			// for all constraints, the inactive ones get multiplier zero
+			farkas := make([]*big.Rat, len(all))
+			for i := range farkas {
+				farkas[i] = new(big.Rat)
+			}
+			for i, y := range res.farkas {
+				farkas[activeIndex[i]] = y
+			}
+			res.farkas = farkas
+			return res
+		case LPFailed:
+			return res
+		case LPUnbounded:
+			if len(active) == len(all) {
+				return res
+			}
+			// the other constraints might bound the program, but we don't know
+			// which one, so just solve the whole program
+			return solveTableau(all, lp.numCols, lp.obj)
+		}
+		violated := make([]violation, 0)
+		for i, c := range all {
+			if isActive[i] {
+				continue
+			}
+			if amount := c.violation(res.values); amount.Sign() > 0 {
+				violated = append(violated, violation{i, amount})
+			}
+		}
+		if len(violated) == 0 {
+			return res
+		}
+		sort.Slice(violated, func(i, j int) bool {
+			return violated[i].amount.Cmp(violated[j].amount) > 0
+		})
+		if len(violated) > batchSize {
+			violated = violated[:batchSize]
+		}
+		for _, v := range violated {
+			active = append(active, all[v.index])
+			activeIndex = append(activeIndex, v.index)
+			isActive[v.index] = true
+		}
+	}
+}
+
+// violation stores by how much a constraint is violated.
+type violation struct {
+	index  int
+	amount *big.Rat
+}
+
+// violation returns by how much the values violate the constraint, the result
+// is ≤ 0 if the constraint is satisfied.
+func (c simplexConstraint) violation(values []*big.Rat) *big.Rat {
+	lhs := new(big.Rat)
+	for _, entry := range c.row {
+		lhs.Add(lhs, new(big.Rat).Mul(entry.val, values[entry.col]))
+	}
+	diff := lhs.Sub(lhs, c.rhs)
+	switch c.ct {
+	case ConstraintLE:
+		return diff
+	case ConstraintGE:
+		return diff.Neg(diff)
+	default:
+		return diff.Abs(diff)
+	}
+}
+
+// solveTableau solves the linear program given by the constraints (without
+// integer constraints) with the two phase simplex method.
+func solveTableau(constraints []simplexConstraint, numCols int, obj []*big.Rat) relaxation {
+	t := newSimplexTableau(constraints, numCols)
+	// phase one: minimize the sum of the artificial variables
+	// the cost row contains the negative sum of all artificial rows
+	hasArtificial := false
+	for i, row := range t.rows {
+		if t.basis[i] < 0 {
+			hasArtificial = true
+			for j, val := range row {
+				if val != nil {
+					t.cost[j] = ratSub(t.cost[j], val)
+				}
+			}
+		}
+	}
+	if hasArtificial {
+		if t.iterate() != LPOptimal {
+			// can't happen since phase one is bounded
+			return relaxation{status: LPFailed}
+		}
+		if rhs := t.cost[t.numCols]; rhs != nil && rhs.Sign() != 0 {
+			return relaxation{status: LPInfeasible, farkas: t.farkasDual()}
+		}
+		t.removeArtificials()
+	}
+	// phase two: setup the cost row for the actual objective
+	t.cost = make([]*big.Rat, t.numCols+1)
+	for j := 0; j < numCols; j++ {
+		if obj[j].Sign() != 0 {
+			t.cost[j] = new(big.Rat).Set(obj[j])
+		}
+	}
+	for i, row := range t.rows {
+		col := t.basis[i]
+		if col < 0 || t.cost[col] == nil {
+			continue
+		}
+		factor := new(big.Rat).Set(t.cost[col])
+		t.subRow(t.cost, row, factor)
+	}
+	if status := t.iterate(); status != LPOptimal {
+		return relaxation{status: status}
+	}
+	values := make([]*big.Rat, numCols)
+	for j := range values {
+		values[j] = new(big.Rat)
+	}
+	for i, col := range t.basis {
+		if col >= 0 && col < numCols {
+			if rhs := t.rows[i][t.numCols]; rhs != nil {
+				values[col].Set(rhs)
+			}
+		}
+	}
+	objVal := new(big.Rat)
+	if rhs := t.cost[t.numCols]; rhs != nil {
+		objVal.Neg(rhs)
+	}
+	return relaxation{status: LPOptimal, values: values, objVal: objVal}
+}
+
+// newSimplexTableau creates the initial tableau.
+// An equality constraint is split into a ≤ and a ≥ constraint, so each row
+// has its own slack / surplus column, this is required for farkasDual.
+// All rows get multiplied by -1 if required s.t. the right hand side is ≥ 0,
+// ≥ rows with right hand side 0 are multiplied by -1 as well.
+// For ≤ rows the slack variable is the initial basic variable, ≥ rows get an
+// artificial variable.
+func newSimplexTableau(constraints []simplexConstraint, numCols int) *simplexTableau {
+	numRows := 0
+	for _, c := range constraints {
+		if c.ct == ConstraintEQ {
+			numRows += 2
+		} else {
+			numRows++
+		}
+	}
+	total := numCols + numRows
+	t := &simplexTableau{rows: make([][]*big.Rat, 0, numRows),
+		cost:    make([]*big.Rat, total+1),
+		basis:   make([]int, 0, numRows),
+		numCols: total,
+		slacks:  make([][]slackInfo, len(constraints)),
+	}
+	slackCol := numCols
+	addRow := func(index int, c simplexConstraint, ct ConstraintType) {
+		row := make([]*big.Rat, total+1)
+		for _, entry := range c.row {
+			row[entry.col] = ratAdd(row[entry.col], entry.val)
+		}
+		if c.rhs.Sign() != 0 {
+			row[total] = new(big.Rat).Set(c.rhs)
+		}
+		// sign is the factor the row gets multiplied with
+		sign := 1
+		if c.rhs.Sign() < 0 || (c.rhs.Sign() == 0 && ct == ConstraintGE) {
+			sign = -1
+			for j, val := range row {
+				if val != nil {
+					row[j] = new(big.Rat).Neg(val)
+				}
+			}
+			if ct == ConstraintLE {
+				ct = ConstraintGE
+			} else {
+				ct = ConstraintLE
+			}
+		}
+		if ct == ConstraintLE {
+			row[slackCol] = big.NewRat(1, 1)
+			t.basis = append(t.basis, slackCol)
+			t.slacks[index] = append(t.slacks[index], slackInfo{slackCol, sign})
+		} else {
+			row[slackCol] = big.NewRat(-1, 1)
+			t.basis = append(t.basis, -1)
+			t.slacks[index] = append(t.slacks[index], slackInfo{slackCol, -sign})
+		}
+		slackCol++
+		t.rows = append(t.rows, row)
+	}
+	for i, c := range constraints {
+		if c.ct == ConstraintEQ {
+			addRow(i, c, ConstraintLE)
+			addRow(i, c, ConstraintGE)
+		} else {
+			addRow(i, c, c.ct)
+		}
+	}
+	return t
+}
+
+// farkasDual computes the Farkas certificate after phase one found that the
+// program is infeasible.
+//
+// It returns a multiplier y(i) for each constraint with y(i) ≥ 0 for ≥
+// constraints and y(i) ≤ 0 for ≤ constraints s.t. the sum of
+// y(i) ⋅ row(i) is ≤ 0 in each column and the sum of y(i) ⋅ rhs(i) is > 0.
+// Because all columns are ≥ 0 this proves that there is no solution.
+//
+// The phase one multipliers of the rows can be read from the reduced costs of
+// the slack columns: for a slack column with coefficient s in the row (that was
+// multiplied with sign) the multiplier is -sign ⋅ s ⋅ reduced cost.
+func (t *simplexTableau) farkasDual() []*big.Rat {
+	res := make([]*big.Rat, len(t.slacks))
+	for i, slacks := range t.slacks {
+		res[i] = new(big.Rat)
+		for _, slack := range slacks {
+			if cost := t.cost[slack.col]; cost != nil {
+				res[i].Sub(res[i], new(big.Rat).Mul(cost, big.NewRat(int64(slack.factor), 1)))
+			}
+		}
+	}
+	return res
+}
+
+// iterate runs the simplex iterations until the cost row has no negative
+// entry. It returns LPOptimal or LPUnbounded.
+func (t *simplexTableau) iterate() LPSolutionType {
+	for {
+		// Bland's rule: choose the first column with a negative reduced cost
+		pivotCol := -1
+		for j := 0; j < t.numCols; j++ {
+			if val := t.cost[j]; val != nil && val.Sign() < 0 {
+				pivotCol = j
+				break
+			}
+		}
+		if pivotCol < 0 {
+			return LPOptimal
+		}
+		// ratio test, ties are broken by the smallest basic column
+		pivotRow := -1
+		var minRatio *big.Rat
+		for i, row := range t.rows {
+			val := row[pivotCol]
+			if val == nil || val.Sign() <= 0 {
+				continue
+			}
+			ratio := new(big.Rat)
+			if rhs := row[t.numCols]; rhs != nil {
+				ratio.Quo(rhs, val)
+			}
+			if pivotRow < 0 {
+				pivotRow, minRatio = i, ratio
+				continue
+			}
+			switch cmp := ratio.Cmp(minRatio); {
+			case cmp < 0, cmp == 0 && t.basis[i] < t.basis[pivotRow]:
+				pivotRow, minRatio = i, ratio
+			}
+		}
+		if pivotRow < 0 {
+			return LPUnbounded
+		}
+		t.pivot(pivotRow, pivotCol)
+	}
+}
+
+// pivot performs a pivot operation on the given row and column.
+func (t *simplexTableau) pivot(pivotRow, pivotCol int) {
+	row := t.rows[pivotRow]
+	inv := new(big.Rat).Inv(row[pivotCol])
+	// store the indices of all non-zero entries, we only need to update these
+	nonZero := make([]int, 0, len(row))
+	for j, val := range row {
+		if val != nil {
+			row[j] = new(big.Rat).Mul(val, inv)
+			nonZero = append(nonZero, j)
+		}
+	}
+	update := func(other []*big.Rat) {
+		factor := other[pivotCol]
+		if factor == nil {
+			return
+		}
+		factor = new(big.Rat).Set(factor)
+		for _, j := range nonZero {
+			prod := new(big.Rat).Mul(factor, row[j])
+			other[j] = ratSub(other[j], prod)
+		}
+		// must be zero now, just to be sure
+		other[pivotCol] = nil
+	}
+	for i, other := range t.rows {
+		if i != pivotRow {
+			update(other)
+		}
+	}
+	update(t.cost)
+	t.basis[pivotRow] = pivotCol
+}
+
+// subRow computes dst = dst - factor ⋅ row.
+func (t *simplexTableau) subRow(dst, row []*big.Rat, factor *big.Rat) {
+	for j, val := range row {
+		if val != nil {
+			dst[j] = ratSub(dst[j], new(big.Rat).Mul(factor, val))
+		}
+	}
+}
+
+// removeArtificials removes all artificial variables from the basis after
+// phase one. They all have value zero, so we can pivot on any other non-zero
+// entry in the row. If there is no such entry the row is redundant and can
+// be removed.
+func (t *simplexTableau) removeArtificials() {
+	for i := 0; i < len(t.rows); i++ {
+		if t.basis[i] >= 0 {
+			continue
+		}
+		pivotCol := -1
+		for j := 0; j < t.numCols; j++ {
+			if t.rows[i][j] != nil {
+				pivotCol = j
+				break
+			}
+		}
+		if pivotCol >= 0 {
+			t.pivot(i, pivotCol)
+		} else {
+			last := len(t.rows) - 1
+			t.rows[i], t.basis[i] = t.rows[last], t.basis[last]
+			t.rows, t.basis = t.rows[:last], t.basis[:last]
+			i--
+		}
+	}
+}
+
+// ratAdd returns a + b where nil is interpreted as zero. The result is nil
+// if the sum is zero.
+func ratAdd(a, b *big.Rat) *big.Rat {
+	switch {
+	case a == nil:
+		if b == nil || b.Sign() == 0 {
+			return nil
+		}
+		return new(big.Rat).Set(b)
+	case b == nil:
+		return a
+	}
+	res := new(big.Rat).Add(a, b)
+	if res.Sign() == 0 {
+		return nil
+	}
+	return res
+}
+
+// ratSub returns a - b where nil is interpreted as zero. The result is nil
+// if the difference is zero.
+func ratSub(a, b *big.Rat) *big.Rat {
+	if b == nil {
+		return a
+	}
+	return ratAdd(a, new(big.Rat).Neg(b))
+}
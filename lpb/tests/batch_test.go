@@ -0,0 +1,197 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	br "github.com/FabianWe/boolrecognition"
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+// sendJobs writes a job for each DNF to a channel and closes it.
+func sendJobs(dnfs []br.ClauseSet, nbvar int) <-chan lpb.Job {
+	jobs := make(chan lpb.Job)
+	go func() {
+		for _, phi := range dnfs {
+			jobs <- lpb.Job{Phi: phi, Nbvar: nbvar}
+		}
+		close(jobs)
+	}()
+	return jobs
+}
+
+func TestConvertAll(t *testing.T) {
+	var dnfs []br.ClauseSet
+	for i := 0; i < 20; i++ {
+		dnfs = append(dnfs, smausDNF, wenzelmannDNF, notRegularDNF)
+	}
+	for _, solver := range []lpb.DNFToLPB{lpb.NewHybridSolver(), lpb.NewLPSolver(lpb.TightenNone)} {
+		seen := make([]bool, len(dnfs))
+		for res := range lpb.ConvertAll(context.Background(), solver, sendJobs(dnfs, 5), 4, false) {
+			if seen[res.Index] {
+				t.Errorf("Got more than one result for job %d", res.Index)
+			}
+			seen[res.Index] = true
+			phi := dnfs[res.Index]
+			if res.Index%3 == 2 {
+				if _, ok := res.Err.(*lpb.NotThresholdError); !ok {
+					t.Errorf("Expected NotThresholdError for DNF %s, got %v", phi, res.Err)
+				}
+				continue
+			}
+			if res.Err != nil || !sameFunction(res.LPB, phi, 5) {
+				t.Errorf("Expected LPB for DNF %s, got %v and error %v", phi, res.LPB, res.Err)
+			}
+		}
+		for i, ok := range seen {
+			if !ok {
+				t.Errorf("Got no result for job %d", i)
+			}
+		}
+	}
+}
+
+func TestConvertAllOrdered(t *testing.T) {
+	var dnfs []br.ClauseSet
+	for i := 0; i < 30; i++ {
+		dnfs = append(dnfs, smausDNF, wenzelmannDNF)
+	}
+	// constSolver can't be cloned, so only one worker is used
+	solvers := []lpb.DNFToLPB{lpb.NewCombinatorialSolver(lpb.NewMinSolver()), constSolver{res: lpb.NewLPB(1, []lpb.LPBCoeff{1})}}
+	for _, solver := range solvers {
+		next := 0
+		for res := range lpb.ConvertAll(context.Background(), solver, sendJobs(dnfs, 5), 4, true) {
+			if res.Index != next || res.Err != nil {
+				t.Errorf("Expected result for job %d, got job %d with error %v", next, res.Index, res.Err)
+			}
+			next++
+		}
+		if next != len(dnfs) {
+			t.Errorf("Expected %d results, got %d", len(dnfs), next)
+		}
+	}
+}
+
+func TestConvertAllCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dnfs := []br.ClauseSet{smausDNF, wenzelmannDNF, smausDNF}
+	for _, ordered := range []bool{false, true} {
+		num := 0
+		for res := range lpb.ConvertAll(ctx, lpb.NewHybridSolver(), sendJobs(dnfs, 5), 2, ordered) {
+			if res.Err != context.Canceled {
+				t.Errorf("Expected context.Canceled, got %v", res.Err)
+			}
+			num++
+		}
+		if num != len(dnfs) {
+			t.Errorf("Expected %d results, got %d", len(dnfs), num)
+		}
+	}
+}
+
+// blockingSolver blocks in Convert until release is closed if nbvar is 0,
+// otherwise it counts the call in started.
+type blockingSolver struct {
+	release <-chan struct{}
+	started *int32
+}
+
+func (s blockingSolver) Convert(phi br.ClauseSet, nbvar int) (*lpb.LPB, error) {
+	return s.ConvertContext(context.Background(), phi, nbvar)
+}
+
+func (s blockingSolver) ConvertContext(ctx context.Context, phi br.ClauseSet, nbvar int) (*lpb.LPB, error) {
+	if nbvar == 0 {
+		<-s.release
+	} else {
+		atomic.AddInt32(s.started, 1)
+	}
+	return lpb.NewLPB(1, make([]lpb.LPBCoeff, nbvar)), nil
+}
+
+func (s blockingSolver) Clone() lpb.DNFToLPB {
+	return s
+}
+
+// TestConvertAllOrderedBounded tests that no more than workers jobs are
+// started while the first job is not done.
+func TestConvertAllOrderedBounded(t *testing.T) {
+	release := make(chan struct{})
+	var started int32
+	solver := blockingSolver{release: release, started: &started}
+	jobs := make(chan lpb.Job)
+	go func() {
+		jobs <- lpb.Job{Nbvar: 0}
+		for i := 0; i < 20; i++ {
+			jobs <- lpb.Job{Nbvar: 1}
+		}
+		close(jobs)
+	}()
+	results := lpb.ConvertAll(context.Background(), solver, jobs, 3, true)
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&started); n > 2 {
+		t.Errorf("Expected at most 2 started jobs while the first job is running, got %d", n)
+	}
+	close(release)
+	next := 0
+	for res := range results {
+		if res.Index != next {
+			t.Errorf("Expected result for job %d, got job %d", next, res.Index)
+		}
+		next++
+	}
+	if next != 21 {
+		t.Errorf("Expected 21 results, got %d", next)
+	}
+}
+
+// panicSolver panics on DNFs without clauses.
+type panicSolver struct{}
+
+func (s panicSolver) Convert(phi br.ClauseSet, nbvar int) (*lpb.LPB, error) {
+	return s.ConvertContext(context.Background(), phi, nbvar)
+}
+
+func (s panicSolver) ConvertContext(ctx context.Context, phi br.ClauseSet, nbvar int) (*lpb.LPB, error) {
+	if len(phi) == 0 {
+		panic("no clauses")
+	}
+	return lpb.NewLPB(1, make([]lpb.LPBCoeff, nbvar)), nil
+}
+
+func (s panicSolver) Clone() lpb.DNFToLPB {
+	return s
+}
+
+// TestConvertAllPanic tests that a panic in the solver is returned as error
+// of the job and doesn't stop the other jobs.
+func TestConvertAllPanic(t *testing.T) {
+	dnfs := []br.ClauseSet{smausDNF, {}, wenzelmannDNF}
+	num := 0
+	for res := range lpb.ConvertAll(context.Background(), panicSolver{}, sendJobs(dnfs, 5), 2, true) {
+		if (res.Err != nil) != (res.Index == 1) {
+			t.Errorf("Expected error only for job 1, got error %v for job %d", res.Err, res.Index)
+		}
+		num++
+	}
+	if num != len(dnfs) {
+		t.Errorf("Expected %d results, got %d", len(dnfs), num)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+// notRegularDNF is x1 x2 ∨ x3 x4, this DNF is not regular.
+var notRegularDNF = br.ClauseSet{{0, 1}, {2, 3}}
+
+func TestNotRegularCertificate(t *testing.T) {
+	for _, regTest := range []bool{true, false} {
+		solver := lpb.NewLPSolver(lpb.TightenNone)
+		solver.RegTest = regTest
+		_, err := solver.Convert(notRegularDNF, 4)
+		cert, ok := err.(*lpb.NotThresholdError)
+		if !ok {
+			t.Errorf("Expected NotThresholdError, got %v", err)
+			continue
+		}
+		if regTest && cert.Regularity == nil {
+			t.Error("Expected regularity certificate")
+		}
+		if cert.Asummability == nil {
+			t.Error("Expected asummability witness")
+		}
+		if !cert.Verify(notRegularDNF, 4) {
+			t.Errorf("Invalid certificate %v", cert)
+		}
+	}
+	combinatorial := lpb.NewCombinatorialSolver(lpb.NewMinSolver())
+	_, err := combinatorial.Convert(notRegularDNF, 4)
+	if cert, ok := err.(*lpb.NotThresholdError); !ok || !cert.Verify(notRegularDNF, 4) {
+		t.Errorf("Expected valid NotThresholdError, got %v", err)
+	}
+	combinatorial.FindCertificate = false
+	_, err = combinatorial.Convert(notRegularDNF, 4)
+	if _, ok := err.(*lpb.NotThresholdError); ok || err == nil {
+		t.Errorf("Expected error of the tree solver without certificate, got %v", err)
+	}
+}
+
+func TestFarkasCertificate(t *testing.T) {
+	phi := readDNFFile("regular.dnf")
+	_, err := lpb.NewLPSolver(lpb.TightenNone).Convert(phi, 9)
+	cert, ok := err.(*lpb.NotThresholdError)
+	if !ok {
+		t.Fatalf("Expected NotThresholdError, got %v", err)
+	}
+	if cert.Regularity != nil {
+		t.Error("Expected no regularity certificate for a regular DNF")
+	}
+	if !cert.Verify(phi, 9) {
+		t.Errorf("Invalid certificate %v", cert)
+	}
+	// change a false point, the witness must be invalid
+	cert.Asummability.FalsePoints[0] = cert.Asummability.TruePoints[0]
+	if cert.Verify(phi, 9) {
+		t.Error("Verify accepted an invalid witness")
+	}
+}
+
+func TestNoCertificate(t *testing.T) {
+	for _, phi := range []br.ClauseSet{smausDNF, wenzelmannDNF} {
+		if cert, err := lpb.FindNotThresholdCertificate(context.Background(), phi, 5); cert != nil || err != nil {
+			t.Errorf("Expected no certificate for threshold function %s, got %v, %v", phi, cert, err)
+		}
+	}
+}
+
+func TestRegularityRightChildren(t *testing.T) {
+	// x1 x3 x4 ∨ x2 x3 x5 is not regular, the right children of the tree must
+	// be split further to find that
+	phi := br.ClauseSet{{0, 2, 3}, {1, 2, 4}}
+	solver := lpb.NewLPSolver(lpb.TightenNone)
+	solver.RegTest = true
+	_, err := solver.Convert(phi, 5)
+	cert, ok := err.(*lpb.NotThresholdError)
+	if !ok {
+		t.Fatalf("Expected NotThresholdError, got %v", err)
+	}
+	if cert.Regularity == nil {
+		t.Error("Expected regularity certificate")
+	}
+	if !cert.Verify(phi, 5) {
+		t.Errorf("Invalid certificate %v", cert)
+	}
+}
+
+// TestRegularityCertificateFromTree tests that the regularity certificate is
+// the violation found by the regularity test of the tree.
+func TestRegularityCertificateFromTree(t *testing.T) {
+	coefficients := make([]lpb.LPBCoeff, 16)
+	for i := range coefficients {
+		coefficients[i] = lpb.LPBCoeff(i + 1)
+	}
+	notRegular := append(br.ClauseSet{{16, 17}, {18, 19}}, lpb.NewLPB(68, coefficients).ToDNF()...)
+	tests := []struct {
+		phi   br.ClauseSet
+		nbvar int
+	}{
+		{notRegularDNF, 4},
+		{br.ClauseSet{{0, 2, 3}, {1, 2, 4}}, 5},
+		{notRegular, 20},
+	}
+	for _, tt := range tests {
+		lp := lpb.NewLinearProgram(tt.phi, tt.nbvar, true, true)
+		lp.Tree.BuildTree()
+		violation, err := lp.Tree.RegularityViolation(context.Background(), lpb.ComputeMTPs(lp.Phi, tt.nbvar))
+		if err != nil || violation == nil {
+			t.Errorf("Expected violation of the regularity, got %v, %v", violation, err)
+			continue
+		}
+		if !violation.Verify(lp.Phi, tt.nbvar) {
+			t.Errorf("Invalid violation %v", violation)
+		}
+		expected := violation.Rename(lp.ReverseRenaming)
+		_, err = lpb.NewLPSolver(lpb.TightenNone).Convert(tt.phi, tt.nbvar)
+		cert, ok := err.(*lpb.NotThresholdError)
+		if !ok || cert.Regularity == nil {
+			t.Errorf("Expected regularity certificate, got %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(cert.Regularity.MTP, expected.MTP) || cert.Regularity.First != expected.First || cert.Regularity.Second != expected.Second {
+			t.Errorf("Expected certificate %v from the tree, got %v", expected, cert.Regularity)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+// bruteForceChow computes the number of true points and the Chow parameters
+// of an LPB by evaluating it on all points.
+func bruteForceChow(l *lpb.LPB) (int64, []int64) {
+	nbvar := len(l.Coefficients)
+	var count int64
+	chow := make([]int64, nbvar)
+	for point := 0; point < (1 << uint(nbvar)); point++ {
+		var sum lpb.LPBCoeff
+		for v, coeff := range l.Coefficients {
+			if point&(1<<uint(v)) != 0 {
+				sum = sum.Add(coeff)
+			}
+		}
+		if sum.Compare(l.Threshold) < 0 {
+			continue
+		}
+		count++
+		for v := range chow {
+			if point&(1<<uint(v)) != 0 {
+				chow[v]++
+			}
+		}
+	}
+	return count, chow
+}
+
+func TestChowParameters(t *testing.T) {
+	tests := []*lpb.LPB{
+		lpb.NewLPB(5, []lpb.LPBCoeff{2, 3, 2, 1}),
+		lpb.NewLPB(7, []lpb.LPBCoeff{1, 2, 5, 3, 3, 2}),
+		lpb.NewLPB(4, []lpb.LPBCoeff{0, 2, 1, 0, 2, 1, 1}),
+		lpb.NewLPB(3, []lpb.LPBCoeff{1, 1, 1, 1, 1}),
+		lpb.NewLPB(3, []lpb.LPBCoeff{1, lpb.PositiveInfinity, 1}),
+		lpb.NewLPB(0, []lpb.LPBCoeff{1, 2}),
+		lpb.NewLPB(10, []lpb.LPBCoeff{1, 2}),
+	}
+	for _, l := range tests {
+		nbvar := len(l.Coefficients)
+		expectedCount, expectedChow := bruteForceChow(l)
+		if count := l.CountModels(); count.Int64() != expectedCount {
+			t.Errorf("Expected %d models for LPB %s, got %s", expectedCount, l, count)
+		}
+		chow := l.ChowParameters()
+		for v, val := range chow {
+			if val.Int64() != expectedChow[v] {
+				t.Errorf("Expected Chow parameters %v for LPB %s, got %v", expectedChow, l, chow)
+				break
+			}
+		}
+		phi := l.ToDNF()
+		if count := phi.CountModels(nbvar); count.Int64() != expectedCount {
+			t.Errorf("Expected %d models for DNF %s, got %s", expectedCount, phi, count)
+		}
+		dnfChow := phi.ChowParameters(nbvar)
+		for v, val := range dnfChow {
+			if val.Int64() != expectedChow[v] {
+				t.Errorf("Expected Chow parameters %v for DNF %s, got %v", expectedChow, phi, dnfChow)
+				break
+			}
+		}
+	}
+}
+
+func TestChowEquals(t *testing.T) {
+	l := lpb.NewLPB(5, []lpb.LPBCoeff{2, 3, 2, 1})
+	if !l.ChowEquals(lpb.NewLPB(10, []lpb.LPBCoeff{4, 6, 4, 2})) {
+		t.Error("Expected scaled LPB to be equivalent")
+	}
+	if l.ChowEquals(lpb.NewLPB(3, []lpb.LPBCoeff{1, 2, 1, 1})) {
+		t.Error("Expected LPBs to be not equivalent")
+	}
+	if l.ChowEquals(lpb.NewLPB(5, []lpb.LPBCoeff{2, 3, 2, 1, 0})) {
+		t.Error("Expected LPBs with a different number of variables to be not equivalent")
+	}
+}
+
+func TestChowSolver(t *testing.T) {
+	solver := lpb.NewChowSolver()
+	tests := []struct {
+		phi   br.ClauseSet
+		nbvar int
+	}{
+		{smausDNF, 5},
+		{wenzelmannDNF, 5},
+		{phi, 4},
+		{br.ClauseSet{}, 3},
+		{br.ClauseSet{{}}, 3},
+		{lpb.NewLPB(2521, []lpb.LPBCoeff{481, 537, 387, 141, 479, 197, 919, 387, 745, 505, 768}).ToDNF(), 11},
+	}
+	for _, tt := range tests {
+		res, err := solver.Convert(tt.phi, tt.nbvar)
+		if err != nil {
+			t.Errorf("Expected no error for DNF %s, got %v", tt.phi, err)
+			continue
+		}
+		if err := lpb.Verify(res, tt.phi, tt.nbvar); err != nil {
+			t.Errorf("LPB %s does not represent DNF %s: %v", res, tt.phi, err)
+		}
+	}
+	_, err := solver.Convert(notRegularDNF, 4)
+	if cert, ok := err.(*lpb.NotThresholdError); !ok || !cert.Verify(notRegularDNF, 4) {
+		t.Errorf("Expected valid NotThresholdError, got %v", err)
+	}
+}
+
+func TestChowSolverNotMinimal(t *testing.T) {
+	// a threshold function with a redundant clause, no updates are allowed so
+	// the solver must give up without a certificate
+	phi := lpb.NewLPB(10, []lpb.LPBCoeff{4, 3, 8, 1, 0, 5}).ToDNF()
+	phi = append(phi, br.Clause{0, 1, 2, 3, 4, 5})
+	solver := &lpb.ChowSolver{MaxUpdates: 0}
+	res, err := solver.Convert(phi, 6)
+	switch err {
+	case nil:
+		if err := lpb.Verify(res, phi, 6); err != nil {
+			t.Errorf("LPB %s does not represent DNF %s: %v", res, phi, err)
+		}
+	case lpb.ErrNotConverged:
+	default:
+		t.Errorf("Expected no error or ErrNotConverged for threshold function %s, got %v", phi, err)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	br "github.com/FabianWe/boolrecognition"
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+func TestConvertContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, solver := range []lpb.DNFToLPB{
+		lpb.NewCombinatorialSolver(lpb.NewMinSolver()),
+		lpb.NewLPSolver(lpb.TightenNone),
+		lpb.NewChowSolver(),
+		lpb.NewHybridSolver(),
+	} {
+		if _, err := solver.ConvertContext(ctx, smausDNF, 5); err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		res, err := solver.ConvertContext(context.Background(), smausDNF, 5)
+		if err != nil || !sameFunction(res, smausDNF, 5) {
+			t.Errorf("Expected LPB for DNF %s, got %v and error %v", smausDNF, res, err)
+		}
+	}
+}
+
+func TestBudgetExceeded(t *testing.T) {
+	comb := lpb.NewCombinatorialSolver(lpb.NewMinSolver())
+	comb.MaxNodes = 3
+	lpSolver := lpb.NewLPSolver(lpb.TightenNone)
+	lpSolver.MaxNodes = 3
+	for _, solver := range []lpb.DNFToLPB{comb, lpSolver} {
+		if _, err := solver.Convert(smausDNF, 5); err != lpb.ErrBudgetExceeded {
+			t.Errorf("Expected ErrBudgetExceeded, got %v", err)
+		}
+	}
+	comb.MaxNodes = 1000
+	lpSolver.MaxNodes = 1000
+	for _, solver := range []lpb.DNFToLPB{comb, lpSolver} {
+		if res, err := solver.Convert(smausDNF, 5); err != nil || !sameFunction(res, smausDNF, 5) {
+			t.Errorf("Expected LPB for DNF %s, got %v and error %v", smausDNF, res, err)
+		}
+	}
+}
+
+func TestSimplexTimeout(t *testing.T) {
+	lp := lpb.NewSimplexLP(2)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 0, Val: 1}, {Col: 1, Val: 1}}, lpb.ConstraintGE, 1)
+	lp.(lpb.TimeoutLPBackend).SetTimeout(time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if res := lp.Solve(); res != lpb.LPFailed {
+		t.Errorf("Expected LPFailed after timeout, got %v", res)
+	}
+	lp.(lpb.TimeoutLPBackend).SetTimeout(0)
+	if res := lp.Solve(); res != lpb.LPOptimal {
+		t.Errorf("Expected LPOptimal without timeout, got %v", res)
+	}
+}
+
+func TestCertificateContext(t *testing.T) {
+	phi := readDNFFile("regular.dnf")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if cert, err := lpb.FindNotThresholdCertificate(ctx, phi, 9); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v and error %v", cert, err)
+	}
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if cert, err := lpb.FindNotThresholdCertificate(ctx, phi, 9); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v and error %v", cert, err)
+	}
+	cert, err := lpb.FindNotThresholdCertificate(context.Background(), phi, 9)
+	if err != nil || cert == nil || !cert.Verify(phi, 9) {
+		t.Errorf("Expected valid certificate, got %v and error %v", cert, err)
+	}
+}
+
+func TestChowSolverDeadline(t *testing.T) {
+	// x1 x2 ∨ x3 x4 ∨ ... ∨ x39 x40 has 2^20 maximal false points, computing
+	// them takes much longer than the deadline
+	nbvar := 40
+	phi := br.NewClauseSet(nbvar / 2)
+	for v := 0; v < nbvar; v += 2 {
+		phi = append(phi, br.Clause{v, v + 1})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := lpb.NewChowSolver().ConvertContext(ctx, phi, nbvar); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Chow solver stopped %s after the deadline", elapsed)
+	}
+}
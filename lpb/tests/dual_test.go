@@ -0,0 +1,59 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+// TestDualizeMFPs tests that the dual of a regular DNF consists of the
+// variables that are false in the maximal false points.
+func TestDualizeMFPs(t *testing.T) {
+	tests := []struct {
+		phi   br.ClauseSet
+		nbvar int
+	}{
+		{smausDNF, 5},
+		{wenzelmannDNF, 5},
+		{phi, 4},
+		{readDNFFile("regular.dnf"), 9},
+	}
+	for _, tt := range tests {
+		lp := lpb.NewLinearProgram(tt.phi, tt.nbvar, true, true)
+		mtps := lpb.ComputeMTPs(lp.Phi, tt.nbvar)
+		mfps := lpb.ComputeMFPs(mtps, true)
+		expected := br.NewClauseSet(len(mfps))
+		for _, mfp := range mfps {
+			clause := br.NewClause(tt.nbvar)
+			for v := 0; v < mfp.Len(); v++ {
+				if !mfp.Test(v) {
+					clause = append(clause, v)
+				}
+			}
+			expected = append(expected, clause)
+		}
+		dual, err := lp.Phi.Dualize(tt.nbvar)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !dual.DeepSortedEquals(expected) {
+			t.Errorf("Dual of %s: expected %s, got %s", lp.Phi, expected, dual)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build golp
+// +build golp
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	br "github.com/FabianWe/boolrecognition"
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+// These tests only run with go test -tags golp, lpsolve must be installed.
+
+var (
+	_ lpb.LPBackend        = &lpb.GolpLP{}
+	_ lpb.TimeoutLPBackend = &lpb.GolpLP{}
+)
+
+func TestGolpRegistered(t *testing.T) {
+	if _, ok := lpb.LPBackends["golp"]; !ok {
+		t.Error("Expected golp to be registered in LPBackends")
+	}
+}
+
+func TestGolpSolutionTypes(t *testing.T) {
+	lp := lpb.NewGolpLP(2)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 0, Val: 1}, {Col: 1, Val: 2}}, lpb.ConstraintLE, 4)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 0, Val: 3}, {Col: 1, Val: 1}}, lpb.ConstraintLE, 6)
+	lp.SetObjFn([]float64{-1, -1})
+	if res := lp.Solve(); res != lpb.LPOptimal {
+		t.Errorf("Expected optimal solution, got %s", res)
+	}
+	lp = lpb.NewGolpLP(2)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 0, Val: 1}, {Col: 1, Val: 1}}, lpb.ConstraintGE, 3)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 0, Val: 1}}, lpb.ConstraintLE, 1)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 1, Val: 1}}, lpb.ConstraintEQ, 1)
+	if res := lp.Solve(); res != lpb.LPInfeasible {
+		t.Errorf("Expected infeasible lp, got %s", res)
+	}
+	lp = lpb.NewGolpLP(1)
+	lp.SetObjFn([]float64{-1})
+	if res := lp.Solve(); res != lpb.LPUnbounded {
+		t.Errorf("Expected unbounded lp, got %s", res)
+	}
+}
+
+func TestGolpSolver(t *testing.T) {
+	phi := br.ClauseSet{br.Clause{0, 1}, br.Clause{0, 2}}
+	solver := lpb.NewLPSolver(lpb.TightenNone)
+	solver.Backend = lpb.LPBackends["golp"]
+	// with a deadline the timeout is passed to lpsolve with SetTimeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	res, err := solver.ConvertContext(ctx, phi, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lpb.Verify(res, phi, 3); err != nil {
+		t.Errorf("golp produced a wrong LPB %s: %v", res, err)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+// constSolver is a DNFToLPB that always returns the same result.
+type constSolver struct {
+	res *lpb.LPB
+	err error
+}
+
+func (s constSolver) Convert(phi br.ClauseSet, nbvar int) (*lpb.LPB, error) {
+	return s.res, s.err
+}
+
+func (s constSolver) ConvertContext(ctx context.Context, phi br.ClauseSet, nbvar int) (*lpb.LPB, error) {
+	return s.res, s.err
+}
+
+func TestHybridSolver(t *testing.T) {
+	solver := lpb.NewHybridSolver()
+	for _, phi := range []br.ClauseSet{smausDNF, wenzelmannDNF} {
+		res, index, err := solver.ConvertReport(phi, 5)
+		if err != nil {
+			t.Errorf("Expected no error for DNF %s, got %v", phi, err)
+			continue
+		}
+		if index != 0 {
+			t.Errorf("Expected combinatorial solver to succeed on DNF %s, got solver %d", phi, index)
+		}
+		if !sameFunction(res, phi, 5) {
+			t.Errorf("LPB %s does not represent DNF %s", res, phi)
+		}
+	}
+	// the combinatorial solver doesn't search for a certificate, the LP solver
+	// finds it
+	_, index, err := solver.ConvertReport(notRegularDNF, 4)
+	if _, ok := err.(*lpb.NotThresholdError); !ok || index != 1 {
+		t.Errorf("Expected NotThresholdError from solver 1, got %v from solver %d", err, index)
+	}
+	_, index, err = lpb.NewHybridSolver(lpb.NewCombinatorialSolver(lpb.NewMinSolver())).ConvertReport(notRegularDNF, 4)
+	if _, ok := err.(*lpb.NotThresholdError); !ok || index != 0 {
+		t.Errorf("Expected NotThresholdError from solver 0, got %v from solver %d", err, index)
+	}
+}
+
+func TestHybridSolverFallback(t *testing.T) {
+	// x1 ≥ 1 is wrong for smausDNF
+	wrong := constSolver{res: lpb.NewLPB(1, []lpb.LPBCoeff{1, 0, 0, 0, 0})}
+	failing := constSolver{err: errors.New("failed")}
+	solver := lpb.NewHybridSolver(failing, wrong, lpb.NewLPSolver(lpb.TightenNone))
+	res, index, err := solver.ConvertReport(smausDNF, 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if index != 2 {
+		t.Errorf("Expected solver 2 to succeed, got %d", index)
+	}
+	if !sameFunction(res, smausDNF, 5) {
+		t.Errorf("LPB %s does not represent DNF %s", res, smausDNF)
+	}
+	solver = lpb.NewHybridSolver(failing, wrong)
+	_, index, err = solver.ConvertReport(smausDNF, 5)
+	if err != lpb.ErrWrongLPB || index != -1 {
+		t.Errorf("Expected ErrWrongLPB and index -1, got %v and %d", err, index)
+	}
+}
+
+// TestHybridSolverConstant tests the false and the true function, the
+// combinatorial solver and the LP solver must not fail on them.
+func TestHybridSolverConstant(t *testing.T) {
+	tests := []struct {
+		phi      br.ClauseSet
+		expected *lpb.LPB
+	}{
+		{br.ClauseSet{}, lpb.NewLPB(1, []lpb.LPBCoeff{0, 0, 0})},
+		{br.ClauseSet{{}}, lpb.NewLPB(0, []lpb.LPBCoeff{0, 0, 0})},
+	}
+	solvers := []*lpb.HybridSolver{lpb.NewHybridSolver(), lpb.NewHybridSolver(lpb.NewLPSolver(lpb.TightenNone))}
+	for _, solver := range solvers {
+		for _, tt := range tests {
+			res, err := solver.Convert(tt.phi, 3)
+			if err != nil {
+				t.Errorf("Expected LPB for DNF %s, got error %v", tt.phi, err)
+				continue
+			}
+			if !res.Equals(tt.expected) {
+				t.Errorf("Expected %s for DNF %s, got %s", tt.expected, tt.phi, res)
+			}
+		}
+	}
+}
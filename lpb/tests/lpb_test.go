@@ -17,6 +17,7 @@ package tests
 import (
 	"testing"
 
+	br "github.com/FabianWe/boolrecognition"
 	"github.com/FabianWe/boolrecognition/lpb"
 )
 
@@ -35,3 +36,71 @@ func TestConvertTrue(t *testing.T) {
 		t.Errorf("LPB %s should be true, but got DNF %s", trueLPB, res)
 	}
 }
+
+func TestConvertUnsorted(t *testing.T) {
+	tests := []*lpb.LPB{
+		lpb.NewLPB(5, []lpb.LPBCoeff{2, 3, 2, 1}),
+		lpb.NewLPB(7, []lpb.LPBCoeff{1, 2, 5, 3, 3, 2}),
+		lpb.NewLPB(4, []lpb.LPBCoeff{0, 2, 1, 0, 2, 1, 1}),
+		lpb.NewLPB(3, []lpb.LPBCoeff{1, 1, 1, 1, 1}),
+	}
+	for _, l := range tests {
+		nbvar := len(l.Coefficients)
+		res := l.ToDNF()
+		if !sameFunction(l, res, nbvar) {
+			t.Errorf("DNF %s does not represent LPB %s", res, l)
+		}
+		if len(res.Minimize()) != len(res) {
+			t.Errorf("DNF %s for LPB %s is not minimal", res, l)
+		}
+	}
+}
+
+func TestStreamDNF(t *testing.T) {
+	// x1 + ... + x40 ≥ 37 has 9880 minimal true points
+	coeffs := make([]lpb.LPBCoeff, 40)
+	for i := range coeffs {
+		coeffs[i] = 1
+	}
+	l := lpb.NewLPB(37, coeffs)
+	count := 0
+	l.StreamDNF(func(clause br.Clause) bool {
+		if len(clause) != 37 {
+			t.Errorf("Expected clause of length 37, got %v", clause)
+		}
+		count++
+		return true
+	})
+	if count != 9880 {
+		t.Errorf("Expected 9880 clauses, got %d", count)
+	}
+	count = 0
+	l.StreamDNF(func(clause br.Clause) bool {
+		count++
+		return count < 10
+	})
+	if count != 10 {
+		t.Errorf("Expected StreamDNF to stop after 10 clauses, got %d", count)
+	}
+}
+
+func TestLPBTruthTable(t *testing.T) {
+	for _, l := range []*lpb.LPB{
+		lpb.NewLPB(5, []lpb.LPBCoeff{2, 3, 2, 1}),
+		lpb.NewLPB(7, []lpb.LPBCoeff{1, 2, 5, 3, 3, 2}),
+		lpb.NewLPB(0, []lpb.LPBCoeff{1, 1}),
+		lpb.NewLPB(10, []lpb.LPBCoeff{1, 1}),
+	} {
+		nbvar := len(l.Coefficients)
+		table := l.TruthTable()
+		if !table.Equals(br.DNFTruthTable(l.ToDNF(), nbvar)) {
+			t.Errorf("Truth table of LPB %s differs from truth table of its DNF", l)
+		}
+		if !sameFunction(l, l.ToDNF(), nbvar) {
+			t.Errorf("LPB %s does not represent its DNF", l)
+		}
+		if point := br.NewBooleanVector(nbvar); l.Eval(point) != table.Eval(point) {
+			t.Errorf("Eval of LPB %s differs from truth table", l)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		l, expected *lpb.LPB
+	}{
+		// gcd
+		{lpb.NewLPB(2, []lpb.LPBCoeff{2, 2}), lpb.NewLPB(1, []lpb.LPBCoeff{1, 1})},
+		// coefficient greater than the threshold
+		{lpb.NewLPB(2, []lpb.LPBCoeff{5, 1, 1}), lpb.NewLPB(2, []lpb.LPBCoeff{2, 1, 1})},
+		// threshold can be lowered, then divided by the gcd
+		{lpb.NewLPB(5, []lpb.LPBCoeff{2, 2, 2}), lpb.NewLPB(3, []lpb.LPBCoeff{1, 1, 1})},
+		// coefficient can be lowered
+		{lpb.NewLPB(12, []lpb.LPBCoeff{9, 6, 4}), lpb.NewLPB(3, []lpb.LPBCoeff{2, 1, 1})},
+		// unused variable
+		{lpb.NewLPB(3, []lpb.LPBCoeff{3, 1}), lpb.NewLPB(1, []lpb.LPBCoeff{1, 0})},
+		// false and true
+		{lpb.NewLPB(10, []lpb.LPBCoeff{1, 2}), lpb.NewLPB(1, []lpb.LPBCoeff{0, 0})},
+		{lpb.NewLPB(0, []lpb.LPBCoeff{1, 2}), lpb.NewLPB(0, []lpb.LPBCoeff{0, 0})},
+		{lpb.NewLPB(lpb.PositiveInfinity, []lpb.LPBCoeff{1, lpb.PositiveInfinity}), lpb.NewLPB(1, []lpb.LPBCoeff{0, 1})},
+	}
+	for _, tt := range tests {
+		res, err := tt.l.Normalize()
+		if err != nil {
+			t.Errorf("Expected no error for %s, got %v", tt.l, err)
+			continue
+		}
+		if !res.Equals(tt.expected) {
+			t.Errorf("Expected %s as normal form of %s, got %s", tt.expected, tt.l, res)
+		}
+		if !res.EquivalentTo(tt.l) || !tt.l.EquivalentTo(res) {
+			t.Errorf("Normal form %s is not equivalent to %s", res, tt.l)
+		}
+	}
+	// the normal form must not change the function
+	for _, l := range []*lpb.LPB{
+		lpb.NewLPB(2521, []lpb.LPBCoeff{481, 537, 387, 141, 479, 197, 919, 387, 745, 505, 768}),
+		lpb.NewLPB(7, []lpb.LPBCoeff{1, 2, 5, 3, 3, 2}),
+		lpb.NewLPB(4, []lpb.LPBCoeff{0, 2, 1, 0, 2, 1, 1}),
+	} {
+		res, err := l.Normalize()
+		if err != nil {
+			t.Errorf("Expected no error for %s, got %v", l, err)
+			continue
+		}
+		if !res.EquivalentTo(l) {
+			t.Errorf("Normal form %s is not equivalent to %s", res, l)
+		}
+		if again, _ := res.Normalize(); !again.Equals(res) {
+			t.Errorf("Normal form %s of %s is not stable", res, l)
+		}
+	}
+	// -∞ is not allowed
+	l := lpb.NewLPB(1, []lpb.LPBCoeff{lpb.NegativeInfinity, 1})
+	if res, err := l.Normalize(); err == nil {
+		t.Errorf("Expected error for %s, got %s", l, res)
+	}
+}
+
+// TestNormalizeRandom tests Normalize on random unsorted LPBs: the result must
+// represent the same function and no coefficient can be lowered by one.
+func TestNormalizeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 300; i++ {
+		n := 1 + r.Intn(6)
+		coeffs := make([]lpb.LPBCoeff, n)
+		for j := range coeffs {
+			coeffs[j] = lpb.LPBCoeff(r.Intn(20))
+		}
+		l := lpb.NewLPB(lpb.LPBCoeff(1+r.Intn(40)), coeffs)
+		res, err := l.Normalize()
+		if err != nil {
+			t.Errorf("Expected no error for %s, got %v", l, err)
+			continue
+		}
+		phi := l.ToDNF()
+		if !sameFunction(res, phi, n) {
+			t.Errorf("Normal form %s is not equivalent to %s", res, l)
+			continue
+		}
+		for j, coeff := range res.Coefficients {
+			if coeff == 0 {
+				continue
+			}
+			lowered := append([]lpb.LPBCoeff(nil), res.Coefficients...)
+			lowered[j]--
+			// the threshold might change as well
+			for d := lpb.LPBCoeff(0); d <= res.Threshold; d++ {
+				if sameFunction(lpb.NewLPB(d, lowered), phi, n) {
+					t.Errorf("Coefficient of x%d in normal form %s of %s can be lowered", j+1, res, l)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestCanonicalForm(t *testing.T) {
+	// different LPBs for the same function must have the same canonical form
+	pairs := [][2]*lpb.LPB{
+		{lpb.NewLPB(25, []lpb.LPBCoeff{24, 1, 11, 30, 17, 21}), lpb.NewLPB(4, []lpb.LPBCoeff{3, 1, 2, 4, 2, 2})},
+	}
+	r := rand.New(rand.NewSource(7))
+	for len(pairs) < 300 {
+		n := 2 + r.Intn(5)
+		coeffs := make([]lpb.LPBCoeff, n)
+		for i := range coeffs {
+			coeffs[i] = lpb.LPBCoeff(r.Intn(13))
+		}
+		l := lpb.NewLPB(lpb.LPBCoeff(1+r.Intn(30)), coeffs)
+		// w ≥ d is the same as k ⋅ w ≥ k ⋅ d - m for m < k because all sums
+		// are multiples of k
+		k := lpb.LPBCoeff(2 + r.Intn(3))
+		scaled := make([]lpb.LPBCoeff, n)
+		for i, c := range coeffs {
+			scaled[i] = k * c
+		}
+		pairs = append(pairs, [2]*lpb.LPB{l, lpb.NewLPB(k*l.Threshold-lpb.LPBCoeff(r.Intn(int(k))), scaled)})
+		// an LPB computed by the LP solver, the variables in the DNF must occur
+		phi := l.ToDNF()
+		if len(phi) == 0 || len(phi[0]) == 0 {
+			continue
+		}
+		if other, err := lpb.NewLPSolver(lpb.TightenNone).Convert(phi, n); err == nil {
+			pairs = append(pairs, [2]*lpb.LPB{l, other})
+		}
+	}
+	for _, pair := range pairs {
+		if !pair[0].EquivalentTo(pair[1]) {
+			t.Fatalf("Test is broken: %s and %s are not equivalent", pair[0], pair[1])
+		}
+		first, err := pair[0].CanonicalForm()
+		if err != nil {
+			t.Errorf("Expected no error for %s, got %v", pair[0], err)
+			continue
+		}
+		second, err := pair[1].CanonicalForm()
+		if err != nil {
+			t.Errorf("Expected no error for %s, got %v", pair[1], err)
+			continue
+		}
+		if first.String() != second.String() {
+			t.Errorf("Different canonical forms %s and %s for equivalent LPBs %s and %s", first, second, pair[0], pair[1])
+		}
+		if !first.EquivalentTo(pair[0]) {
+			t.Errorf("Canonical form %s is not equivalent to %s", first, pair[0])
+		}
+	}
+}
+
+func TestEquivalentTo(t *testing.T) {
+	l := lpb.NewLPB(5, []lpb.LPBCoeff{2, 3, 2, 1})
+	if !l.EquivalentTo(lpb.NewLPB(10, []lpb.LPBCoeff{4, 6, 4, 2})) {
+		t.Error("Expected scaled LPB to be equivalent")
+	}
+	if l.EquivalentTo(lpb.NewLPB(3, []lpb.LPBCoeff{1, 2, 1, 1})) {
+		t.Error("Expected LPBs to be not equivalent")
+	}
+	if l.EquivalentTo(lpb.NewLPB(5, []lpb.LPBCoeff{2, 3, 2, 1, 0})) {
+		t.Error("Expected LPBs with a different number of variables to be not equivalent")
+	}
+}
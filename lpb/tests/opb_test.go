@@ -0,0 +1,108 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+func TestParseOPB(t *testing.T) {
+	input := `* #variable= 4 #constraint= 3
+* a comment
+min: +1 x1 ;
++2 x1 +1 x2
+  +1 x3 >= 2 ;
++1 x3 +1 x3 >= 1;
++1 x2 >= -1 ;
+`
+	lpbs, names, err := lpb.ParseOPB(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []*lpb.LPB{
+		lpb.NewLPB(2, []lpb.LPBCoeff{2, 1, 1, 0}),
+		lpb.NewLPB(1, []lpb.LPBCoeff{0, 0, 2, 0}),
+		lpb.NewLPB(0, []lpb.LPBCoeff{0, 1, 0, 0}),
+	}
+	if len(lpbs) != len(expected) {
+		t.Fatalf("Expected %d LPBs, got %d", len(expected), len(lpbs))
+	}
+	for i, l := range lpbs {
+		if !l.Equals(expected[i]) {
+			t.Errorf("Expected LPB %s, got %s", expected[i], l)
+		}
+	}
+	if strings.Join(names, " ") != "x1 x2 x3 x4" {
+		t.Errorf("Expected names x1 x2 x3 x4, got %v", names)
+	}
+}
+
+func TestParseOPBNames(t *testing.T) {
+	input := "+3 b +1 a >= 2 ;\n+1 a +1 c >= 1 ;\n"
+	lpbs, names, err := lpb.ParseOPB(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Join(names, " ") != "b a c" {
+		t.Errorf("Expected names b a c, got %v", names)
+	}
+	if len(lpbs) != 2 || !lpbs[1].Equals(lpb.NewLPB(1, []lpb.LPBCoeff{0, 1, 1})) {
+		t.Errorf("Unexpected result %v", lpbs)
+	}
+}
+
+func TestParseOPBErrors(t *testing.T) {
+	for _, input := range []string{
+		"+1 x1 +1 x2 <= 1 ;",
+		"+1 x1 = 1 ;",
+		"-1 x1 >= 1 ;",
+		"+1 ~x1 >= 1 ;",
+		"+1 x1 x2 >= 1 ;",
+		"+1 x1 >= 1",
+		"+1 x1 >= a ;",
+	} {
+		if _, _, err := lpb.ParseOPB(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected error for input \"%s\"", input)
+		}
+	}
+}
+
+func TestWriteOPB(t *testing.T) {
+	lpbs := []*lpb.LPB{
+		lpb.NewLPB(2, []lpb.LPBCoeff{2, 1, 0}),
+		lpb.NewLPB(1, []lpb.LPBCoeff{0, 0, 0}),
+	}
+	var buffer bytes.Buffer
+	if err := lpb.WriteOPB(&buffer, lpbs, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := "* #variable= 3 #constraint= 2\n+2 x1 +1 x2 >= 2 ;\n+0 x1 >= 1 ;\n"
+	if buffer.String() != expected {
+		t.Errorf("Expected output\n%s\ngot\n%s", expected, buffer.String())
+	}
+	res, _, err := lpb.ParseOPB(&buffer)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for i, l := range res {
+		if !l.Equals(lpbs[i]) {
+			t.Errorf("Expected LPB %s, got %s", lpbs[i], l)
+		}
+	}
+}
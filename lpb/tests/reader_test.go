@@ -0,0 +1,83 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+func TestParseLPB(t *testing.T) {
+	expected := lpb.NewLPB(2, []lpb.LPBCoeff{2, 1, 1})
+	for _, str := range []string{"2 1 1 2", "2\t1  1 2 ", " 2 1 1 2\r", "2 1 1 2 # comment"} {
+		res, err := lpb.ParseLPB(str)
+		if err != nil {
+			t.Errorf("Expected no error for \"%s\", got %v", str, err)
+			continue
+		}
+		if !res.Equals(expected) {
+			t.Errorf("Expected %s for \"%s\", got %s", expected, str, res)
+		}
+	}
+	tests := []struct {
+		str    string
+		column int
+	}{
+		{"", 1},
+		{"   ", 1},
+		{"c comment", 1},
+		{"2 1 a 2", 5},
+		{"2  -1 1 2", 4},
+		{"2 1 1 2x", 7},
+		{"2 1 1 -2", 7},
+	}
+	for _, tt := range tests {
+		_, err := lpb.ParseLPB(tt.str)
+		syntaxErr, ok := err.(*lpb.SyntaxError)
+		if !ok {
+			t.Errorf("Expected SyntaxError for \"%s\", got %v", tt.str, err)
+			continue
+		}
+		if syntaxErr.Line != 1 || syntaxErr.Column != tt.column {
+			t.Errorf("Expected error in line 1, column %d for \"%s\", got %v", tt.column, tt.str, err)
+		}
+	}
+}
+
+func TestReadLPBs(t *testing.T) {
+	input := "c benchmark\r\n2 1 1 2\r\n\r\n# comment\r\n3 2 1 1 4\r\n"
+	res, err := lpb.ReadLPBs(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []*lpb.LPB{
+		lpb.NewLPB(2, []lpb.LPBCoeff{2, 1, 1}),
+		lpb.NewLPB(4, []lpb.LPBCoeff{3, 2, 1, 1}),
+	}
+	if len(res) != len(expected) {
+		t.Fatalf("Expected %d LPBs, got %d", len(expected), len(res))
+	}
+	for i, l := range res {
+		if !l.Equals(expected[i]) {
+			t.Errorf("Expected %s, got %s", expected[i], l)
+		}
+	}
+	_, err = lpb.ReadLPBs(strings.NewReader("2 1 1 2\n\n2 1 x 2\n"))
+	if syntaxErr, ok := err.(*lpb.SyntaxError); !ok || syntaxErr.Line != 3 || syntaxErr.Column != 5 {
+		t.Errorf("Expected error in line 3, column 5, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+// regularTree builds the tree for the regularity test and computes the
+// minimal true points.
+func regularTree(phi br.ClauseSet, nbvar int) (*lpb.DNFTree, []*br.BitVector) {
+	lp := lpb.NewLinearProgram(phi, nbvar, true, true)
+	lp.Tree.BuildTree()
+	return lp.Tree, lpb.ComputeMTPs(lp.Phi, nbvar)
+}
+
+func TestTreeIsRegular(t *testing.T) {
+	coefficients := make([]lpb.LPBCoeff, 16)
+	for i := range coefficients {
+		coefficients[i] = lpb.LPBCoeff(i + 1)
+	}
+	threshold := lpb.NewLPB(68, coefficients).ToDNF()
+	// x17 x18 ∨ x19 x20 is not regular
+	notRegular := append(br.ClauseSet{{16, 17}, {18, 19}}, threshold...)
+	tests := []struct {
+		phi      br.ClauseSet
+		nbvar    int
+		expected bool
+	}{
+		{threshold, 16, true},
+		{notRegular, 20, false},
+		{notRegularDNF, 4, false},
+		{smausDNF, 5, true},
+	}
+	for _, tt := range tests {
+		tree, mtps := regularTree(tt.phi, tt.nbvar)
+		before := make([]*br.BitVector, len(mtps))
+		for i, mtp := range mtps {
+			before[i] = mtp.Clone()
+		}
+		regular, err := tree.IsRegular(context.Background(), mtps)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+			continue
+		}
+		if regular != tt.expected {
+			t.Errorf("Expected regular = %v for DNF with %d clauses, got %v", tt.expected, len(tt.phi), regular)
+		}
+		for i, mtp := range mtps {
+			if !mtp.Equals(before[i]) {
+				t.Errorf("IsRegular changed point %s to %s", before[i], mtp)
+			}
+		}
+	}
+}
+
+func TestTreeIsRegularCancel(t *testing.T) {
+	tree, mtps := regularTree(smausDNF, 5)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if regular, err := tree.IsRegular(ctx, mtps); regular || err != context.Canceled {
+		t.Errorf("Expected false and context.Canceled, got %v and %v", regular, err)
+	}
+}
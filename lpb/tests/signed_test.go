@@ -0,0 +1,146 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+// negateVariables transforms a positive DNF to a general DNF where the given
+// variables are negated.
+func negateVariables(phi br.ClauseSet, negated ...int) br.ClauseSet {
+	isNegated := make(map[int]bool)
+	for _, v := range negated {
+		isNegated[v] = true
+	}
+	res := br.NewClauseSet(len(phi))
+	for _, clause := range phi {
+		newClause := br.NewClause(len(clause))
+		for _, v := range clause {
+			if isNegated[v] {
+				newClause = append(newClause, -(v + 1))
+			} else {
+				newClause = append(newClause, v+1)
+			}
+		}
+		res = append(res, newClause)
+	}
+	return res
+}
+
+// sameSignedFunction checks if the signed LPB and the general DNF represent
+// the same function by evaluating both on all points.
+func sameSignedFunction(l *lpb.SignedLPB, phi br.ClauseSet, nbvar int) bool {
+	for point := 0; point < (1 << uint(nbvar)); point++ {
+		isSet := func(v int) bool {
+			return point&(1<<uint(v)) != 0
+		}
+		sum := 0
+		for v, coeff := range l.Coefficients {
+			if isSet(v) {
+				sum += coeff
+			}
+		}
+		dnfVal := false
+		for _, clause := range phi {
+			clauseVal := true
+			for _, literal := range clause {
+				if (literal > 0) != isSet(abs(literal)-1) {
+					clauseVal = false
+					break
+				}
+			}
+			if clauseVal {
+				dnfVal = true
+				break
+			}
+		}
+		if dnfVal != (sum >= l.Threshold) {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func TestSignedConverter(t *testing.T) {
+	tests := []struct {
+		phi   br.ClauseSet
+		nbvar int
+	}{
+		{negateVariables(smausDNF, 1, 3), 5},
+		{negateVariables(wenzelmannDNF, 0, 1, 2, 3, 4), 5},
+		// x1 ¬x2 ∨ x1 ¬x2 x3 ∨ x3 x4 ¬x4 ∨ ¬x2 x4, x5 does not occur
+		{br.ClauseSet{{1, -2}, {1, -2, 3}, {3, 4, -4}, {-2, 4}}, 5},
+		{br.ClauseSet{}, 2},
+		{br.ClauseSet{{-1}, {}}, 2},
+		// x1 ∨ x2 ∨ x1 ¬x2 and x1 ∨ ¬x1 x2 are both x1 ∨ x2
+		{br.ClauseSet{{1}, {2}, {1, -2}}, 2},
+		{br.ClauseSet{{1}, {-1, 2}}, 2},
+	}
+	for _, converter := range []lpb.DNFToLPB{lpb.NewLPSolver(lpb.TightenNone), lpb.NewCombinatorialSolver(lpb.NewMinSolver())} {
+		solver := lpb.NewSignedConverter(converter)
+		for _, tt := range tests {
+			res, err := solver.Convert(tt.phi, tt.nbvar)
+			if err != nil {
+				t.Errorf("Expected LPB for DNF %s, got error %s", tt.phi, err)
+				continue
+			}
+			if !sameSignedFunction(res, tt.phi, tt.nbvar) {
+				t.Errorf("Wrong LPB %s for DNF %s", res, tt.phi)
+			}
+		}
+	}
+}
+
+func TestSignedConverterBinate(t *testing.T) {
+	phi := br.ClauseSet{{1, 2}, {-2, 3}}
+	unateness, err := lpb.VariableUnateness(phi, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []lpb.Unateness{lpb.PositiveUnate, lpb.Binate, lpb.PositiveUnate, lpb.Unused}
+	for i, u := range expected {
+		if unateness[i] != u {
+			t.Errorf("Expected variable x%d to be %s, got %s", i+1, u, unateness[i])
+		}
+	}
+	_, err = lpb.NewSignedConverter(lpb.NewLPSolver(lpb.TightenNone)).Convert(phi, 4)
+	if binate, ok := err.(*lpb.BinateError); !ok || binate.Variable != 1 {
+		t.Errorf("Expected BinateError for variable 1, got %v", err)
+	}
+	// binate in the syntax, but x1 ∨ x2 ∨ x1 ¬x2 is x1 ∨ x2
+	unateness, err = lpb.VariableUnateness(br.ClauseSet{{1}, {2}, {1, -2}}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, u := range unateness {
+		if u != lpb.PositiveUnate {
+			t.Errorf("Expected variable x%d of x1 ∨ x2 ∨ x1 ¬x2 to be positive unate, got %s", i+1, u)
+		}
+	}
+	if _, err = lpb.VariableUnateness(br.ClauseSet{{1, 5}}, 4); err == nil {
+		t.Error("Expected error for invalid literal")
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+// TestSimplexOptimal tests a small lp with a known optimal solution:
+// min -x - y s.t. x + 2y ≤ 4, 3x + y ≤ 6 ⇒ x = 8/5, y = 6/5.
+// With integer constraints on both variables the solution is x = 2, y = 0
+// (or x = 1, y = 1 etc., objective is -2).
+func TestSimplexOptimal(t *testing.T) {
+	lp := lpb.NewSimplexLP(2)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 0, Val: 1}, {Col: 1, Val: 2}}, lpb.ConstraintLE, 4)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 0, Val: 3}, {Col: 1, Val: 1}}, lpb.ConstraintLE, 6)
+	lp.SetObjFn([]float64{-1, -1})
+	if res := lp.Solve(); res != lpb.LPOptimal {
+		t.Fatalf("Expected optimal solution, got %s", res)
+	}
+	vars := lp.Variables()
+	if vars[0] != 1.6 || vars[1] != 1.2 {
+		t.Errorf("Expected solution (1.6, 1.2), got %v", vars)
+	}
+	lp.SetInt(0, true)
+	lp.SetInt(1, true)
+	if res := lp.Solve(); res != lpb.LPOptimal {
+		t.Fatalf("Expected optimal solution, got %s", res)
+	}
+	vars = lp.Variables()
+	if vars[0]+vars[1] != 2 {
+		t.Errorf("Expected integer solution with objective -2, got %v", vars)
+	}
+}
+
+func TestSimplexInfeasible(t *testing.T) {
+	lp := lpb.NewSimplexLP(2)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 0, Val: 1}, {Col: 1, Val: 1}}, lpb.ConstraintGE, 3)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 0, Val: 1}}, lpb.ConstraintLE, 1)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 1, Val: 1}}, lpb.ConstraintEQ, 1)
+	if res := lp.Solve(); res != lpb.LPInfeasible {
+		t.Errorf("Expected infeasible lp, got %s", res)
+	}
+	// x + y ≥ 1, 2x + 2y ≤ 1 has a solution, but no integer solution
+	lp = lpb.NewSimplexLP(2)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 0, Val: 2}, {Col: 1, Val: 2}}, lpb.ConstraintEQ, 1)
+	if res := lp.Solve(); res != lpb.LPOptimal {
+		t.Errorf("Expected optimal solution, got %s", res)
+	}
+	lp.SetInt(0, true)
+	lp.SetInt(1, true)
+	if res := lp.Solve(); res != lpb.LPInfeasible {
+		t.Errorf("Expected infeasible lp, got %s", res)
+	}
+}
+
+func TestSimplexUnbounded(t *testing.T) {
+	lp := lpb.NewSimplexLP(2)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 0, Val: 1}, {Col: 1, Val: -1}}, lpb.ConstraintLE, 1)
+	lp.SetObjFn([]float64{-1, 0})
+	if res := lp.Solve(); res != lpb.LPUnbounded {
+		t.Errorf("Expected unbounded lp, got %s", res)
+	}
+}
+
+// sameFunction checks if the LPB and the DNF represent the same function by
+// evaluating both on all points.
+func sameFunction(l *lpb.LPB, phi br.ClauseSet, nbvar int) bool {
+	for point := 0; point < (1 << uint(nbvar)); point++ {
+		isSet := func(v int) bool {
+			return point&(1<<uint(v)) != 0
+		}
+		var sum lpb.LPBCoeff
+		for v, coeff := range l.Coefficients {
+			if isSet(v) {
+				sum += coeff
+			}
+		}
+		dnfVal := false
+		for _, clause := range phi {
+			clauseVal := true
+			for _, v := range clause {
+				if !isSet(v) {
+					clauseVal = false
+					break
+				}
+			}
+			if clauseVal {
+				dnfVal = true
+				break
+			}
+		}
+		if dnfVal != (sum >= l.Threshold) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLPSolverSimplex(t *testing.T) {
+	for _, tighten := range []lpb.TightenMode{lpb.TightenNone, lpb.TightenNeighbours, lpb.TightenAll} {
+		solver := lpb.NewLPSolver(tighten)
+		for _, phi := range []br.ClauseSet{smausDNF, wenzelmannDNF} {
+			res, err := solver.Convert(phi, 5)
+			if err != nil {
+				t.Errorf("Expected LPB for DNF %s, got error %s", phi, err)
+				continue
+			}
+			if !sameFunction(res, phi, 5) {
+				t.Errorf("Wrong LPB %s for DNF %s", res, phi)
+			}
+		}
+	}
+}
+
+// TestSolveLPExact tests that fractional solutions are scaled correctly:
+// w - d ≥ 0 and 2d ≥ 1 has the solution w = d = 1/2, so we should get the
+// LPB 1⋅x1 ≥ 1.
+func TestSolveLPExact(t *testing.T) {
+	lp := lpb.NewSimplexLP(2)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 0, Val: 1}, {Col: 1, Val: -1}}, lpb.ConstraintGE, 0)
+	lp.AddConstraintSparse([]lpb.LPEntry{{Col: 1, Val: 2}}, lpb.ConstraintGE, 1)
+	lp.SetObjFn([]float64{1, 1})
+	res, err := lpb.SolveLP(lp, 1)
+	if err != nil {
+		t.Fatal("Expected LPB, got error", err)
+	}
+	expected := lpb.NewLPB(1, []lpb.LPBCoeff{1})
+	if !res.Equals(expected) {
+		t.Errorf("Expected LPB %s, got %s", expected, res)
+	}
+}
+
+func TestLPSolverIntegral(t *testing.T) {
+	solver := lpb.NewLPSolver(lpb.TightenNone)
+	solver.Exact = false
+	for _, phi := range []br.ClauseSet{smausDNF, wenzelmannDNF} {
+		res, err := solver.Convert(phi, 5)
+		if err != nil {
+			t.Errorf("Expected LPB for DNF %s, got error %s", phi, err)
+			continue
+		}
+		if !sameFunction(res, phi, 5) {
+			t.Errorf("Wrong LPB %s for DNF %s", res, phi)
+		}
+	}
+}
+
+// intRecorder wraps a backend and records calls of SetInt.
+type intRecorder struct {
+	lpb.LPBackend
+	intCalls *int
+}
+
+func (r intRecorder) SetInt(col int, mustBeInt bool) {
+	*r.intCalls++
+	r.LPBackend.SetInt(col, mustBeInt)
+}
+
+// exactIntRecorder is an intRecorder that also implements ExactLPBackend.
+type exactIntRecorder struct {
+	intRecorder
+}
+
+func (r exactIntRecorder) RatVariables() []*big.Rat {
+	return r.LPBackend.(lpb.ExactLPBackend).RatVariables()
+}
+
+// TestLPSolverExactBackend tests that exactness is decided by the backend
+// created by the factory: A factory that wraps NewSimplexLP is still exact and
+// doesn't get integer constraints, a backend that doesn't implement
+// ExactLPBackend is solved with integer constraints, even if Exact is true.
+func TestLPSolverExactBackend(t *testing.T) {
+	tests := []struct {
+		exact bool
+	}{
+		{true},
+		{false},
+	}
+	for _, tt := range tests {
+		intCalls := 0
+		backend := func(numCols int) lpb.LPBackend {
+			rec := intRecorder{LPBackend: lpb.NewSimplexLP(numCols), intCalls: &intCalls}
+			if tt.exact {
+				return exactIntRecorder{rec}
+			}
+			return rec
+		}
+		solver := lpb.NewLPSolver(lpb.TightenNone)
+		solver.Backend = backend
+		for _, phi := range []br.ClauseSet{smausDNF, wenzelmannDNF} {
+			res, err := solver.Convert(phi, 5)
+			if err != nil {
+				t.Errorf("Expected LPB for DNF %s, got error %s", phi, err)
+				continue
+			}
+			if !sameFunction(res, phi, 5) {
+				t.Errorf("Wrong LPB %s for DNF %s", res, phi)
+			}
+		}
+		if tt.exact && intCalls != 0 {
+			t.Errorf("Expected no integer constraints for an exact backend, got %d", intCalls)
+		}
+		if !tt.exact && intCalls == 0 {
+			t.Error("Expected integer constraints for a backend that is not exact")
+		}
+	}
+}
+
+// TestLPSolverObjective tests the objectives on the examples, for both examples
+// the minimal realization is unique.
+func TestLPSolverObjective(t *testing.T) {
+	tests := []struct {
+		phi      br.ClauseSet
+		expected *lpb.LPB
+	}{
+		{smausDNF, lpb.NewLPB(5, []lpb.LPBCoeff{4, 3, 2, 2, 1})},
+		{wenzelmannDNF, lpb.NewLPB(8, []lpb.LPBCoeff{5, 3, 3, 2, 1})},
+	}
+	for _, objective := range []lpb.Objective{lpb.ObjectiveThreshold, lpb.ObjectiveSum, lpb.ObjectiveMax} {
+		solver := lpb.NewLPSolver(lpb.TightenNone)
+		solver.Objective = objective
+		for _, tt := range tests {
+			res, err := solver.Convert(tt.phi, 5)
+			if err != nil {
+				t.Errorf("Expected LPB for DNF %s, got error %s", tt.phi, err)
+				continue
+			}
+			if !res.Equals(tt.expected) {
+				t.Errorf("Expected LPB %s with objective %s, got %s", tt.expected, objective, res)
+			}
+		}
+	}
+}
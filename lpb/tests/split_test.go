@@ -192,4 +192,72 @@ func TestWenzelmannMin(t *testing.T) {
 	}
 }
 
-// TODO test true and false!
+func TestSymmetry(t *testing.T) {
+	// all variables in x1 x2 ∨ x3 x4 have the same occurrence pattern, but
+	// x1 and x3 are not symmetric
+	// in x1 x3 ∨ x1 x4 ∨ x2 x4 x1 and x4 have the same pattern
+	for _, phi := range []br.ClauseSet{{{0, 1}, {2, 3}}, {{0, 2}, {0, 3}, {1, 3}}} {
+		tree := lpb.NewSplittingTree(phi, 4, true, true)
+		if err := tree.CreateTree(); err != lpb.ErrNotSymmetric {
+			t.Errorf("Expected ErrNotSymmetric for DNF %s, got %v", phi, err)
+		}
+	}
+	for _, phi := range []br.ClauseSet{smausDNF, wenzelmannDNF} {
+		tree := lpb.NewSplittingTree(phi, 5, true, true)
+		if err := tree.CreateTree(); err != nil {
+			t.Errorf("Expected no error for DNF %s, got %v", phi, err)
+		}
+	}
+}
+
+func TestNoSharedNode(t *testing.T) {
+	// an aux node whose upper sibling has an upper child without a lower child:
+	// there is no node to share
+	phi := br.ClauseSet{{0}, {1}}
+	context := lpb.NewTreeContext(2)
+	sibling := lpb.NewMainNode(nil, nil, phi, nil, context)
+	sibling.SetUpperChild(lpb.NewMainNode(sibling, nil, br.ClauseSet{{}}, nil, context))
+	aux := lpb.NewAuxNode(nil, sibling, phi, nil, context, 2, 0)
+	if err := aux.Split(false, false); err != lpb.ErrNoSharedNode {
+		t.Errorf("Expected ErrNoSharedNode without symmetry test, got %v", err)
+	}
+	if err := aux.Split(true, false); err != lpb.ErrNotSymmetric {
+		t.Errorf("Expected ErrNotSymmetric with symmetry test, got %v", err)
+	}
+}
+
+// TestCombinatorialConstant tests the false and the true function and DNFs
+// with variables that don't occur, these variables must get coefficient 0.
+func TestCombinatorialConstant(t *testing.T) {
+	tests := []struct {
+		phi      br.ClauseSet
+		nbvar    int
+		expected *lpb.LPB
+	}{
+		{br.ClauseSet{}, 2, lpb.NewLPB(1, []lpb.LPBCoeff{0, 0})},
+		{br.ClauseSet{{}}, 2, lpb.NewLPB(0, []lpb.LPBCoeff{0, 0})},
+		{br.ClauseSet{{0}}, 2, lpb.NewLPB(1, []lpb.LPBCoeff{1, 0})},
+		{br.ClauseSet{{1}}, 3, lpb.NewLPB(1, []lpb.LPBCoeff{0, 1, 0})},
+	}
+	solver := lpb.NewCombinatorialSolver(lpb.NewMinSolver())
+	for _, tt := range tests {
+		res, err := solver.Convert(tt.phi, tt.nbvar)
+		if err != nil {
+			t.Errorf("Expected LPB for DNF %s, got error %v", tt.phi, err)
+			continue
+		}
+		if !res.Equals(tt.expected) {
+			t.Errorf("Expected %s for DNF %s, got %s", tt.expected, tt.phi, res)
+		}
+	}
+	// x1 x2 ∨ x3 x4 is not a threshold function, the certificate must be
+	// computed for all five variables
+	_, err := solver.Convert(br.ClauseSet{{0, 1}, {2, 3}}, 5)
+	cert, ok := err.(*lpb.NotThresholdError)
+	if !ok {
+		t.Fatalf("Expected NotThresholdError, got %v", err)
+	}
+	if cert.Regularity != nil && len(cert.Regularity.MTP) != 5 {
+		t.Errorf("Expected certificate with points of length 5, got %v", cert.Regularity.MTP)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"math/rand"
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+	"github.com/FabianWe/boolrecognition/lpb"
+)
+
+func TestVerify(t *testing.T) {
+	// the Crama example phi is represented by 2 ⋅ x1 + 3 ⋅ x2 + 2 ⋅ x3 + 1 ⋅ x4 ≥ 5,
+	// also test it with the variables in reverse order
+	correct := lpb.NewLPB(5, []lpb.LPBCoeff{2, 3, 2, 1})
+	if err := lpb.Verify(correct, phi, 4); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	renamed := br.ClauseSet{{3, 2}, {3, 1, 0}, {2, 1}}
+	reversed := lpb.NewLPB(5, []lpb.LPBCoeff{1, 2, 3, 2})
+	if err := lpb.Verify(reversed, renamed, 4); err != nil {
+		t.Errorf("Expected no error for reversed LPB, got %v", err)
+	}
+	tests := []struct {
+		lpb       *lpb.LPB
+		truePoint bool
+	}{
+		// x1 x3 x4 is a true point of phi but not of the LPB
+		{lpb.NewLPB(5, []lpb.LPBCoeff{2, 3, 2, 0}), true},
+		// x1 x3 is a false point of phi but a true point of the LPB
+		{lpb.NewLPB(4, []lpb.LPBCoeff{2, 3, 2, 1}), false},
+	}
+	for _, test := range tests {
+		err := lpb.Verify(test.lpb, phi, 4)
+		counter, ok := err.(*lpb.CounterexampleError)
+		if !ok {
+			t.Errorf("Expected CounterexampleError for LPB %s, got %v", test.lpb, err)
+			continue
+		}
+		if counter.TruePoint != test.truePoint {
+			t.Errorf("Expected true point %v for LPB %s, got %v", test.truePoint, test.lpb, counter.TruePoint)
+		}
+		var sum lpb.LPBCoeff
+		for v, val := range counter.Point {
+			if val {
+				sum = sum.Add(test.lpb.Coefficients[v])
+			}
+		}
+		if sum.Lesser(test.lpb.Threshold) != test.truePoint {
+			t.Errorf("Counterexample %v is not a counterexample for LPB %s", counter.Point, test.lpb)
+		}
+	}
+	// constant functions
+	constFalse := lpb.NewLPB(3, []lpb.LPBCoeff{1, 1})
+	constTrue := lpb.NewLPB(0, []lpb.LPBCoeff{1, 1})
+	if err := lpb.Verify(constFalse, br.ClauseSet{}, 2); err != nil {
+		t.Errorf("Expected no error for false, got %v", err)
+	}
+	if err := lpb.Verify(constTrue, br.ClauseSet{{}}, 2); err != nil {
+		t.Errorf("Expected no error for true, got %v", err)
+	}
+	if counter, ok := lpb.Verify(constFalse, br.ClauseSet{{0}}, 2).(*lpb.CounterexampleError); !ok || !counter.TruePoint {
+		t.Errorf("Expected true point as counterexample for %s", constFalse)
+	}
+	if counter, ok := lpb.Verify(constTrue, br.ClauseSet{{0}}, 2).(*lpb.CounterexampleError); !ok || counter.TruePoint {
+		t.Errorf("Expected false point as counterexample for %s", constTrue)
+	}
+	if err := lpb.Verify(correct, phi, 5); err == nil {
+		t.Error("Expected error for wrong number of variables")
+	}
+}
+
+// TestVerifyRandom compares Verify with a test of all points on random
+// unsorted LPBs and DNFs. The DNFs are either the DNF of the LPB or of an LPB
+// with a different threshold.
+func TestVerifyRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for i := 0; i < 200; i++ {
+		nbvar := 1 + r.Intn(7)
+		coeffs := make([]lpb.LPBCoeff, nbvar)
+		var sum lpb.LPBCoeff
+		for v := range coeffs {
+			coeffs[v] = lpb.LPBCoeff(r.Intn(6))
+			sum += coeffs[v]
+		}
+		threshold := lpb.LPBCoeff(1 + r.Intn(int(sum)+1))
+		l := lpb.NewLPB(threshold, coeffs)
+		other := lpb.NewLPB(threshold+lpb.LPBCoeff(r.Intn(3)-1), coeffs)
+		phi := other.ToDNF()
+		err := lpb.Verify(l, phi, nbvar)
+		if expected := sameFunction(l, phi, nbvar); expected != (err == nil) {
+			t.Errorf("Expected same function = %v for LPB %s and DNF %s, got %v", expected, l, phi, err)
+			continue
+		}
+		counter, ok := err.(*lpb.CounterexampleError)
+		if err != nil && !ok {
+			t.Errorf("Expected CounterexampleError for LPB %s, got %v", l, err)
+			continue
+		}
+		if ok && phi.EvalDNF(counter.Point) != counter.TruePoint {
+			t.Errorf("Counterexample %v for LPB %s and DNF %s has the wrong value in the DNF", counter.Point, l, phi)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lpb
+
+import (
+	"fmt"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+// CounterexampleError is returned by Verify if the LPB does not represent the
+// DNF.
+//
+// Point is a point on which the LPB and the DNF differ. If TruePoint is true
+// it is the point of a clause of the DNF (so a true point of the DNF) and a
+// false point of the LPB, otherwise it is a maximal false point of the DNF and
+// a true point of the LPB.
+type CounterexampleError struct {
+	Point     br.BooleanVector
+	TruePoint bool
+}
+
+func (err *CounterexampleError) Error() string {
+	if err.TruePoint {
+		return fmt.Sprintf("%v is a true point of the DNF but a false point of the LPB", err.Point)
+	}
+	return fmt.Sprintf("%v is a maximal false point of the DNF but a true point of the LPB", err.Point)
+}
+
+// Verify checks if the LPB represents the positive DNF ϕ.
+//
+// Because both are monotone this is the case iff each clause of ϕ is a true
+// point of the LPB and each maximal false point of ϕ is a false point of the
+// LPB. The first test simply sums up the coefficients of each clause, this is
+// linear in the size of ϕ. For the second test the maximal false points of ϕ
+// are enumerated with a depth first search, the search skips all points that
+// can't be extended to a true point of the LPB. So the DNF of the LPB is never
+// computed and only the current point is stored, but the search can still take
+// exponential time if ϕ has many maximal false points that are close to the
+// threshold. Neither the LPB nor ϕ must be sorted or minimal.
+//
+// It returns nil if the LPB represents ϕ and a *CounterexampleError with the
+// first point that was found otherwise (clauses are checked first).
+// If the LPB doesn't have nbvar coefficients, contains a coefficient -∞ or ϕ
+// contains invalid variables another error is returned.
+func Verify(lpb *LPB, phi br.ClauseSet, nbvar int) error {
+	if len(lpb.Coefficients) != nbvar {
+		return fmt.Errorf("LPB has %d coefficients, expected %d", len(lpb.Coefficients), nbvar)
+	}
+	// the variables of each clause without duplicates
+	clauses := make([]br.Clause, len(phi))
+	for i, clause := range phi {
+		for _, v := range clause {
+			if v < 0 || v >= nbvar {
+				return fmt.Errorf("Invalid variable %d in Verify, must be in the range 0 ≤ v < %d", v, nbvar)
+			}
+		}
+		clauses[i] = clause.Normalize()
+	}
+	lpb, err := lpb.finite()
+	if err != nil {
+		return err
+	}
+	for _, clause := range clauses {
+		var sum LPBCoeff
+		for _, v := range clause {
+			sum += lpb.Coefficients[v]
+		}
+		if sum < lpb.Threshold {
+			point := br.NewBooleanVector(nbvar)
+			for _, v := range clause {
+				point[v] = true
+			}
+			return &CounterexampleError{Point: point, TruePoint: true}
+		}
+	}
+	search := newMFPSearch(lpb, clauses)
+	if point := search.find(); point != nil {
+		return &CounterexampleError{Point: point, TruePoint: false}
+	}
+	return nil
+}
+
+// mfpSearch is used in Verify to find a maximal false point of ϕ that is a
+// true point of the LPB.
+//
+// The variables are assigned one after another, first 1 and then 0. A
+// variable can only be set to 1 if it doesn't make a clause true. A branch is
+// cut once the LPB can't become true, even if all remaining variables are set
+// to 1.
+type mfpSearch struct {
+	lpb         *LPB
+	occurrences [][]int
+	// unset stores for each clause the number of variables that are not set to
+	// 1, the clause is true once this is 0
+	unset []int
+	// remaining[v] is the sum of the coefficients of v, ..., n - 1
+	remaining []LPBCoeff
+	point     br.BooleanVector
+}
+
+// newMFPSearch returns a new search, each clause must not contain duplicates.
+func newMFPSearch(lpb *LPB, clauses []br.Clause) *mfpSearch {
+	n := len(lpb.Coefficients)
+	occurrences := make([][]int, n)
+	unset := make([]int, len(clauses))
+	for i, clause := range clauses {
+		unset[i] = len(clause)
+		for _, v := range clause {
+			occurrences[v] = append(occurrences[v], i)
+		}
+	}
+	remaining := make([]LPBCoeff, n+1)
+	for v := n - 1; v >= 0; v-- {
+		remaining[v] = remaining[v+1] + lpb.Coefficients[v]
+	}
+	return &mfpSearch{lpb: lpb,
+		occurrences: occurrences,
+		unset:       unset,
+		remaining:   remaining,
+		point:       br.NewBooleanVector(n),
+	}
+}
+
+// find returns the first maximal false point of ϕ that is a true point of the
+// LPB or nil if there is no such point.
+func (s *mfpSearch) find() br.BooleanVector {
+	for _, count := range s.unset {
+		if count == 0 {
+			// ϕ contains an empty clause, so it has no false points
+			return nil
+		}
+	}
+	return s.search(0, 0)
+}
+
+// search assigns the variables v, ..., n - 1, weight is the sum of the
+// coefficients of the variables that are set to 1.
+func (s *mfpSearch) search(v int, weight LPBCoeff) br.BooleanVector {
+	if weight+s.remaining[v] < s.lpb.Threshold {
+		return nil
+	}
+	if v == len(s.point) {
+		if s.isMaximal() {
+			return s.point.Clone()
+		}
+		return nil
+	}
+	// try to set v to 1
+	canSet := true
+	for _, c := range s.occurrences[v] {
+		if s.unset[c] == 1 {
+			canSet = false
+			break
+		}
+	}
+	if canSet {
+		for _, c := range s.occurrences[v] {
+			s.unset[c]--
+		}
+		s.point[v] = true
+		res := s.search(v+1, weight+s.lpb.Coefficients[v])
+		s.point[v] = false
+		for _, c := range s.occurrences[v] {
+			s.unset[c]++
+		}
+		if res != nil {
+			return res
+		}
+	}
+	return s.search(v+1, weight)
+}
+
+// isMaximal checks if the current (false) point is a maximal false point,
+// i.e. setting any variable that is 0 to 1 makes a clause true.
+func (s *mfpSearch) isMaximal() bool {
+	for v, val := range s.point {
+		if val {
+			continue
+		}
+		blocked := false
+		for _, c := range s.occurrences[v] {
+			if s.unset[c] == 1 {
+				blocked = true
+				break
+			}
+		}
+		if !blocked {
+			return false
+		}
+	}
+	return true
+}
+
+// streamMFPs calls f for each maximal false point of the LPB, if f returns
+// false no more points are computed. The LPB must not contain ∞ or -∞, see
+// finite.
+//
+// x is a maximal false point iff the variables that are false in x are a
+// minimal set s.t. the sum of their coefficients is > sum - threshold, these
+// sets are the minimal true points of the LPB with the same coefficients and
+// threshold sum - threshold + 1.
+func (lpb *LPB) streamMFPs(f func(point br.BooleanVector) bool) {
+	nbvar := len(lpb.Coefficients)
+	var sum LPBCoeff
+	for _, coeff := range lpb.Coefficients {
+		sum += coeff
+	}
+	switch {
+	case lpb.Threshold == 0:
+		// the LPB is true, there are no false points
+		return
+	case sum < lpb.Threshold:
+		// the LPB is false, the only maximal false point is the one point
+		point := br.NewBooleanVector(nbvar)
+		for v := range point {
+			point[v] = true
+		}
+		f(point)
+		return
+	}
+	dual := NewLPB(sum-lpb.Threshold+1, lpb.Coefficients)
+	dual.StreamDNF(func(clause br.Clause) bool {
+		point := br.NewBooleanVector(nbvar)
+		for v := range point {
+			point[v] = true
+		}
+		for _, v := range clause {
+			point[v] = false
+		}
+		return f(point)
+	})
+}
+
+// finite returns an LPB without ∞ and -∞ that represents the same function.
+// Coefficients that are ∞ are replaced by the threshold, if the threshold is
+// ∞ they are replaced by 1 and all other coefficients by 0.
+// A coefficient -∞ is not allowed.
+func (lpb *LPB) finite() (*LPB, error) {
+	n := len(lpb.Coefficients)
+	coeffs := make([]LPBCoeff, n)
+	for i, coeff := range lpb.Coefficients {
+		switch coeff {
+		case NegativeInfinity:
+			return nil, fmt.Errorf("Coefficient of x%d is %s", i+1, coeff)
+		case PositiveInfinity:
+			coeffs[i] = lpb.Threshold
+		default:
+			coeffs[i] = coeff
+		}
+	}
+	switch lpb.Threshold {
+	case NegativeInfinity:
+		return NewLPB(0, make([]LPBCoeff, n)), nil
+	case PositiveInfinity:
+		for i, coeff := range lpb.Coefficients {
+			if coeff == PositiveInfinity {
+				coeffs[i] = 1
+			} else {
+				coeffs[i] = 0
+			}
+		}
+		return NewLPB(1, coeffs), nil
+	}
+	return NewLPB(lpb.Threshold, coeffs), nil
+}
@@ -0,0 +1,297 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boolrecognition
+
+import "sort"
+
+// This file contains methods to compute the prime implicants of a DNF.
+//
+// The methods work on positive DNFs (variables start with 0) and on general
+// DNFs where literals are represented as in DIMACS: the variable v is
+// represented by v ≠ 0 and ¬v by -v.
+// Two literals l1 and l2 are complementary iff l1 = -l2 ≠ 0, so positive DNFs
+// never contain complementary literals.
+
+// Normalize returns a sorted copy of the clause without duplicate literals.
+func (c Clause) Normalize() Clause {
+	res := make(Clause, len(c))
+	copy(res, c)
+	res.Sort()
+	if len(res) == 0 {
+		return res
+	}
+	n := 1
+	for _, l := range res[1:] {
+		if l != res[n-1] {
+			res[n] = l
+			n++
+		}
+	}
+	return res[:n]
+}
+
+// IsContradiction checks if the clause contains complementary literals, i.e.
+// the conjunction of the literals is always false.
+func (c Clause) IsContradiction() bool {
+	for i, l1 := range c {
+		if l1 == 0 {
+			continue
+		}
+		for _, l2 := range c[i+1:] {
+			if l1 == -l2 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsSubsetOf checks if each literal of c is also contained in other.
+// Both clauses must be sorted and must not contain duplicates (see Normalize).
+func (c Clause) IsSubsetOf(other Clause) bool {
+	if len(c) > len(other) {
+		return false
+	}
+	j := 0
+	for _, l := range c {
+		for j < len(other) && other[j] < l {
+			j++
+		}
+		if j == len(other) || other[j] != l {
+			return false
+		}
+		j++
+	}
+	return true
+}
+
+// compareClauses compares two sorted clauses: shorter clauses are smaller,
+// clauses of the same length are compared lexicographically.
+func compareClauses(c1, c2 Clause) int {
+	if len(c1) != len(c2) {
+		return len(c1) - len(c2)
+	}
+	for i, l := range c1 {
+		if l != c2[i] {
+			return l - c2[i]
+		}
+	}
+	return 0
+}
+
+// Minimize removes duplicate and subsumed clauses from the DNF (absorption:
+// x ∨ x y = x). It also removes clauses that contain complementary literals
+// and duplicate literals in each clause.
+//
+// The result is a new DNF, the clauses in the result are sorted and ordered
+// by length (and lexicographically for clauses of the same length).
+// ϕ itself is not changed.
+//
+// For a positive DNF the result is the set of all prime implicants of ϕ and
+// thus the minimal DNF required by the solvers in the lpb package. For
+// general DNFs use PrimeImplicants.
+func (phi ClauseSet) Minimize() ClauseSet {
+	clauses := NewClauseSet(len(phi))
+	for _, clause := range phi {
+		if !clause.IsContradiction() {
+			clauses = append(clauses, clause.Normalize())
+		}
+	}
+	sort.Slice(clauses, func(i, j int) bool {
+		return compareClauses(clauses[i], clauses[j]) < 0
+	})
+	res := NewClauseSet(len(clauses))
+	for _, clause := range clauses {
+		// all clauses that might subsume clause are already in res
+		if !res.subsumes(clause) {
+			res = append(res, clause)
+		}
+	}
+	return res
+}
+
+// subsumes checks if a clause in ϕ is a subset of c.
+func (phi ClauseSet) subsumes(c Clause) bool {
+	for _, other := range phi {
+		if other.IsSubsetOf(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// consensus computes the consensus of two sorted clauses: If there is exactly
+// one literal l s.t. l ∈ c1 and -l ∈ c2 the consensus is (c1 ∪ c2) \ {l, -l}.
+// The second return value is false if there is no consensus.
+func consensus(c1, c2 Clause) (Clause, bool) {
+	opposed := 0
+	var literal int
+	for _, l1 := range c1 {
+		if l1 == 0 {
+			continue
+		}
+		for _, l2 := range c2 {
+			if l1 == -l2 {
+				opposed++
+				literal = l1
+			}
+		}
+	}
+	if opposed != 1 {
+		return nil, false
+	}
+	res := NewClause(len(c1) + len(c2) - 2)
+	for _, l := range c1 {
+		if l != literal {
+			res = append(res, l)
+		}
+	}
+	for _, l := range c2 {
+		if l != -literal {
+			res = append(res, l)
+		}
+	}
+	return res.Normalize(), true
+}
+
+// PrimeImplicants computes all prime implicants of the DNF ϕ with the
+// iterated consensus method: As long as there are two clauses with a
+// consensus that is not subsumed by another clause the consensus gets added
+// and all clauses subsumed by it are removed.
+//
+// The result is ordered as described in Minimize.
+// For positive DNFs there are no consensus, so this is the same as Minimize.
+// Note that the number of prime implicants can be exponential in the size
+// of ϕ.
+func (phi ClauseSet) PrimeImplicants() ClauseSet {
+	res := phi.Minimize()
+	for changed := true; changed; {
+		changed = false
+		for i := 0; i < len(res); i++ {
+			for j := i + 1; j < len(res); j++ {
+				c, hasConsensus := consensus(res[i], res[j])
+				if !hasConsensus || res.subsumes(c) {
+					continue
+				}
+				// remove all clauses subsumed by c and add c
+				newRes := NewClauseSet(len(res) + 1)
+				for _, clause := range res {
+					if !c.IsSubsetOf(clause) {
+						newRes = append(newRes, clause)
+					}
+				}
+				res = append(newRes, c)
+				changed = true
+			}
+		}
+	}
+	return res.Minimize()
+}
+
+// Irredundant computes an irredundant DNF consisting of prime implicants of
+// ϕ, that is no clause can be removed without changing the function.
+//
+// It first computes all prime implicants and then removes (starting with the
+// longest clauses) each clause that is implied by the remaining clauses.
+// The result is not necessarily a DNF with the minimal number of clauses.
+// For positive DNFs all prime implicants are required, so the result is the
+// same as Minimize.
+func (phi ClauseSet) Irredundant() ClauseSet {
+	primes := phi.PrimeImplicants()
+	removed := make([]bool, len(primes))
+	for i := len(primes) - 1; i >= 0; i-- {
+		others := NewClauseSet(len(primes))
+		for j, clause := range primes {
+			if j != i && !removed[j] {
+				others = append(others, clause)
+			}
+		}
+		if others.Implied(primes[i]) {
+			removed[i] = true
+		}
+	}
+	res := NewClauseSet(len(primes))
+	for i, clause := range primes {
+		if !removed[i] {
+			res = append(res, clause)
+		}
+	}
+	return res
+}
+
+// Implied checks if the conjunction of the literals in c implies ϕ.
+//
+// This is the case iff ϕ is a tautology if all literals in c are set to true.
+func (phi ClauseSet) Implied(c Clause) bool {
+	if c.IsContradiction() {
+		return true
+	}
+	restricted := phi
+	for _, l := range c {
+		restricted = restricted.assign(l)
+	}
+	return restricted.IsTautology()
+}
+
+// assign returns the DNF we get if the literal l is set to true: Clauses that
+// contain -l are removed and l is removed from all other clauses.
+func (phi ClauseSet) assign(l int) ClauseSet {
+	res := NewClauseSet(len(phi))
+	for _, clause := range phi {
+		newClause := NewClause(len(clause))
+		contradiction := false
+		for _, other := range clause {
+			switch {
+			case other == l:
+			case other == -l:
+				contradiction = true
+			default:
+				newClause = append(newClause, other)
+			}
+			if contradiction {
+				break
+			}
+		}
+		if !contradiction {
+			res = append(res, newClause)
+		}
+	}
+	return res
+}
+
+// IsTautology checks if the DNF ϕ is always true.
+//
+// It uses Shannon expansion on the literals in ϕ, if no variable occurs
+// positive and negated the DNF is a tautology iff it contains the empty
+// clause.
+func (phi ClauseSet) IsTautology() bool {
+	occurrences := make(map[int]bool)
+	binate := 0
+	for _, clause := range phi {
+		if len(clause) == 0 {
+			return true
+		}
+		for _, l := range clause {
+			occurrences[l] = true
+			if l != 0 && occurrences[-l] {
+				binate = l
+			}
+		}
+	}
+	if binate == 0 {
+		return false
+	}
+	return phi.assign(binate).IsTautology() && phi.assign(-binate).IsTautology()
+}
@@ -0,0 +1,124 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boolrecognition
+
+import "fmt"
+
+// RegularityViolation explains why a positive DNF is not regular: The
+// variables First and Second are incomparable, neither of them is at least as
+// strong as the other one.
+//
+// FirstPoint is a minimal true point with First = 0 and Second = 1 s.t.
+// swapping the values of First and Second yields a false point, thus First is
+// not at least as strong as Second. SecondPoint is a minimal true point with
+// Second = 0 and First = 1 s.t. swapping yields a false point.
+type RegularityViolation struct {
+	First, Second           int
+	FirstPoint, SecondPoint BooleanVector
+}
+
+func (v *RegularityViolation) String() string {
+	return fmt.Sprintf("x%d and x%d are incomparable: swapping them in %s and in %s yields false points",
+		v.First+1, v.Second+1, v.FirstPoint, v.SecondPoint)
+}
+
+// IsRegular checks if the positive DNF ϕ is regular, that is if the
+// variables can be ordered by their strength.
+//
+// A variable i is at least as strong as a variable j if for each point x
+// with x[i] = 0 and x[j] = 1 swapping i and j in x does not change a true
+// point into a false point. This relation is transitive, ϕ is regular iff
+// each two variables are comparable. Each threshold function is regular.
+//
+// If ϕ is regular it returns the variables ordered by their strength, the
+// strongest variable comes first. Variables of the same strength occur in an
+// arbitrary order. Otherwise it returns a witness for two incomparable
+// variables.
+//
+// The candidate order is computed by sorting the Winder matrix, if i is
+// stronger than j the row of i is greater than the row of j. This order is
+// then sorted by insertion sort comparing the strength of the variables
+// directly, so a wrong candidate order only costs some more comparisons.
+// If the Winder order is correct each variable is only compared with its
+// predecessor.
+// ϕ does not have to be minimal, variables in ϕ must be in the range
+// 0 ≤ v < nbvar.
+func IsRegular(phi ClauseSet, nbvar int) (order []int, witness *RegularityViolation) {
+	phi = phi.Minimize()
+	winder := NewWinderMatrix(phi, nbvar, true)
+	winder.Sort()
+	order = make([]int, nbvar)
+	for i, row := range winder {
+		order[i] = row[nbvar]
+	}
+	// after inserting order[k] each variable in order[:k+1] is at least as
+	// strong as its successor, by transitivity the order is correct
+	for k := 1; k < nbvar; k++ {
+		for m := k; m > 0; m-- {
+			first, second := order[m-1], order[m]
+			firstPoint := strengthViolation(phi, nbvar, first, second)
+			if firstPoint == nil {
+				break
+			}
+			secondPoint := strengthViolation(phi, nbvar, second, first)
+			if secondPoint != nil {
+				return nil, &RegularityViolation{First: first, Second: second,
+					FirstPoint: firstPoint, SecondPoint: secondPoint}
+			}
+			// second is stronger than first
+			order[m-1], order[m] = second, first
+		}
+	}
+	return order, nil
+}
+
+// strengthViolation tests if the variable i is at least as strong as j in the
+// minimal DNF ϕ. If so it returns nil, otherwise it returns a minimal true
+// point with i = 0 and j = 1 s.t. swapping i and j yields a false point.
+//
+// It suffices to test the minimal true points, i.e. the clauses of ϕ: Each
+// true point x contains a minimal true point m, if m[j] = 0 the swapped point
+// still contains m and otherwise swapped(x) contains swapped(m).
+func strengthViolation(phi ClauseSet, nbvar, i, j int) BooleanVector {
+	for _, clause := range phi {
+		containsI, containsJ := false, false
+		for _, v := range clause {
+			switch v {
+			case i:
+				containsI = true
+			case j:
+				containsJ = true
+			}
+		}
+		if containsI || !containsJ {
+			continue
+		}
+		swapped := NewClause(len(clause))
+		for _, v := range clause {
+			if v != j {
+				swapped = append(swapped, v)
+			}
+		}
+		swapped = append(swapped, i).Normalize()
+		if !phi.subsumes(swapped) {
+			point := NewBooleanVector(nbvar)
+			for _, v := range clause {
+				point[v] = true
+			}
+			return point
+		}
+	}
+	return nil
+}
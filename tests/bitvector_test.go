@@ -0,0 +1,72 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+func TestBitVector(t *testing.T) {
+	// use more than one word
+	v := br.NewBitVector(130)
+	for _, i := range []int{0, 63, 64, 100, 129} {
+		v.Set(i)
+	}
+	v.Set(5)
+	v.Clear(5)
+	if v.Len() != 130 || v.Count() != 5 {
+		t.Errorf("Expected length 130 and 5 set entries, got %d and %d", v.Len(), v.Count())
+	}
+	var set []int
+	for i := v.NextSet(0); i >= 0; i = v.NextSet(i + 1) {
+		set = append(set, i)
+	}
+	if len(set) != 5 || set[0] != 0 || set[1] != 63 || set[2] != 64 || set[3] != 100 || set[4] != 129 {
+		t.Errorf("Expected set entries [0 63 64 100 129], got %v", set)
+	}
+	clone := v.Clone()
+	clone.Clear(129)
+	if !v.Test(129) || clone.Test(129) {
+		t.Error("Changing a clone changed the original vector")
+	}
+	if v.Compare(clone) <= 0 || clone.Compare(v) >= 0 || v.Compare(v.Clone()) != 0 {
+		t.Errorf("Wrong comparison of %s and %s", v, clone)
+	}
+	if d := v.FirstDifference(clone); d != 129 {
+		t.Errorf("Expected first difference 129, got %d", d)
+	}
+	if !br.NewBitVectorFrom(v.ToBooleanVector()).Equals(v) {
+		t.Errorf("Conversion to BooleanVector and back changed %s", v)
+	}
+}
+
+func TestBitVectorCompare(t *testing.T) {
+	points := []br.BooleanVector{
+		{false, false, true},
+		{false, true, false},
+		{false, true, true},
+		{true, false, false},
+	}
+	for i, p1 := range points {
+		for j, p2 := range points {
+			res := br.NewBitVectorFrom(p1).Compare(br.NewBitVectorFrom(p2))
+			if (i < j && res >= 0) || (i == j && res != 0) || (i > j && res <= 0) {
+				t.Errorf("Wrong comparison of %s and %s: %d", p1, p2, res)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+func TestCountModels(t *testing.T) {
+	tests := []struct {
+		phi   br.ClauseSet
+		count int64
+		chow  []int64
+	}{
+		// false and true
+		{br.ClauseSet{}, 0, []int64{0, 0, 0, 0}},
+		{br.ClauseSet{{}}, 16, []int64{8, 8, 8, 8}},
+		// x1 x2 ∨ x3 x4
+		{br.ClauseSet{{0, 1}, {2, 3}}, 7, []int64{5, 5, 5, 5}},
+		// x1 ∨ x2 x3, x4 doesn't occur
+		{br.ClauseSet{{0}, {1, 2}, {0, 1}}, 10, []int64{8, 6, 6, 5}},
+	}
+	for _, tt := range tests {
+		if count := tt.phi.CountModels(4); count.Int64() != tt.count {
+			t.Errorf("Expected %d models for DNF %s, got %s", tt.count, tt.phi, count)
+		}
+		chow := tt.phi.ChowParameters(4)
+		for v, val := range chow {
+			if val.Int64() != tt.chow[v] {
+				t.Errorf("Expected Chow parameters %v for DNF %s, got %v", tt.chow, tt.phi, chow)
+				break
+			}
+		}
+	}
+}
+
+func TestCountModelsContext(t *testing.T) {
+	phi := br.ClauseSet{{0, 1}, {2, 3}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := phi.CountModelsContext(ctx, 4); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := phi.ChowParametersContext(ctx, 4); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+func TestParseDIMACS(t *testing.T) {
+	input := `c first comment
+p cnf 3 3
+1 -2 0
+c second comment
+-3
+2 0
+0
+`
+	info, phi, err := br.ParseDIMACS(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !info.IsCNF() || info.IsDNF() || info.NbVar != 3 || info.NbClauses != 3 {
+		t.Errorf("Unexpected info %v", info)
+	}
+	if len(info.Comments) != 2 || info.Comments[0] != "first comment" || info.Comments[1] != "second comment" {
+		t.Errorf("Unexpected comments %v", info.Comments)
+	}
+	expected := br.ClauseSet{{1, -2}, {-3, 2}, {}}
+	if !phi.DeepSortedEquals(expected) {
+		t.Errorf("Expected %v, got %v", expected, phi)
+	}
+	var buffer bytes.Buffer
+	if err := info.WriteDIMACS(&buffer, phi); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	info2, phi2, err := br.ParseDIMACS(&buffer)
+	if err != nil {
+		t.Fatalf("Expected no error parsing written DIMACS, got %v", err)
+	}
+	if info2.Problem != "cnf" || len(info2.Comments) != 2 || !phi2.DeepSortedEquals(expected) {
+		t.Errorf("Written DIMACS differs: %v %v", info2, phi2)
+	}
+}
+
+func TestParseDIMACSErrors(t *testing.T) {
+	for _, input := range []string{
+		// no problem line
+		"1 2 0\n",
+		// clause before problem line
+		"1 2 0\np dnf 2 1\n",
+		// too many and too few clauses
+		"p dnf 2 1\n1 0\n2 0\n",
+		"p dnf 2 3\n1 0\n2 0\n",
+		// invalid literals
+		"p dnf 2 1\n1 3 0\n",
+		"p dnf 2 1\n1 -3 0\n",
+		"p dnf 2 1\n1 x 0\n",
+		// invalid problem line
+		"p dnf 2\n1 0\n",
+	} {
+		if _, _, err := br.ParseDIMACS(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected error for input %q", input)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+func TestDualize(t *testing.T) {
+	tests := []struct {
+		phi, expected br.ClauseSet
+	}{
+		// false and true
+		{br.ClauseSet{}, br.ClauseSet{{}}},
+		{br.ClauseSet{{}}, br.ClauseSet{}},
+		// x1 x2 ∨ x3 ⇒ (x1 ∨ x3)(x2 ∨ x3)
+		{br.ClauseSet{{0, 1}, {2}}, br.ClauseSet{{0, 2}, {1, 2}}},
+		// the majority function is self-dual
+		{br.ClauseSet{{0, 1}, {0, 2}, {1, 2}}, br.ClauseSet{{0, 1}, {0, 2}, {1, 2}}},
+		// x1 x2 ∨ x3 x4
+		{br.ClauseSet{{0, 1}, {2, 3}}, br.ClauseSet{{0, 2}, {0, 3}, {1, 2}, {1, 3}}},
+	}
+	for _, tt := range tests {
+		dual, err := tt.phi.Dualize(4)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !dual.DeepSortedEquals(tt.expected) {
+			t.Errorf("Dual of %s: expected %s, got %s", tt.phi, tt.expected, dual)
+		}
+		// dualization is an involution
+		dualDual, _ := dual.Dualize(4)
+		if !dualDual.DeepSortedEquals(tt.phi.Minimize()) {
+			t.Errorf("Dual of dual of %s: got %s", tt.phi, dualDual)
+		}
+	}
+	if _, err := (br.ClauseSet{{0, 4}}).Dualize(4); err == nil {
+		t.Error("Expected error for variable out of range")
+	}
+}
+
+func TestWriteCNFDIMACS(t *testing.T) {
+	phi := br.ClauseSet{{0, 2}, {1, 2}}
+	buffer := new(bytes.Buffer)
+	if err := phi.WriteCNFDIMACS(buffer, 3, true); err != nil {
+		t.Fatal(err)
+	}
+	expected := "p cnf 3 2\n1 3 0\n2 3 0\n"
+	if buffer.String() != expected {
+		t.Errorf("Expected output %q, got %q", expected, buffer.String())
+	}
+}
+
+func TestDualizeContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := (br.ClauseSet{{0, 1}, {2, 3}}).DualizeContext(ctx, 4); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+func TestMinimize(t *testing.T) {
+	tests := []struct {
+		phi, expected br.ClauseSet
+	}{
+		{br.ClauseSet{}, br.ClauseSet{}},
+		{br.ClauseSet{{1, 0}, {0}, {3, 2, 2}, {2, 3}, {4, 2, 3}}, br.ClauseSet{{0}, {2, 3}}},
+		{br.ClauseSet{{2, 1}, {1, 2, 0}, {}}, br.ClauseSet{{}}},
+		// contradictions are removed
+		{br.ClauseSet{{1, -1}, {2, -3}, {2, -3, 4}}, br.ClauseSet{{-3, 2}}},
+	}
+	for _, tt := range tests {
+		res := tt.phi.Minimize()
+		if !res.SortedEquals(tt.expected) {
+			t.Errorf("Minimize of %s: expected %s, got %s", tt.phi, tt.expected, res)
+		}
+	}
+}
+
+func TestPrimeImplicants(t *testing.T) {
+	tests := []struct {
+		phi, primes, irredundant br.ClauseSet
+	}{
+		// x1 x2 ∨ ¬x1 x3: the consensus x2 x3 is redundant
+		{br.ClauseSet{{1, 2}, {-1, 3}}, br.ClauseSet{{-1, 3}, {1, 2}, {2, 3}}, br.ClauseSet{{-1, 3}, {1, 2}}},
+		// x1 x2 ∨ x1 ¬x2 = x1
+		{br.ClauseSet{{1, 2}, {1, -2}}, br.ClauseSet{{1}}, br.ClauseSet{{1}}},
+		// x1 ∨ ¬x1 x2 = x1 ∨ x2
+		{br.ClauseSet{{1}, {-1, 2}}, br.ClauseSet{{1}, {2}}, br.ClauseSet{{1}, {2}}},
+		// x1 ∨ ¬x1 is a tautology
+		{br.ClauseSet{{1}, {-1}}, br.ClauseSet{{}}, br.ClauseSet{{}}},
+		// positive DNFs have no consensus
+		{br.ClauseSet{{0, 1}, {2, 1}, {0, 2}, {0, 1, 2}}, br.ClauseSet{{0, 1}, {0, 2}, {1, 2}}, br.ClauseSet{{0, 1}, {0, 2}, {1, 2}}},
+	}
+	for _, tt := range tests {
+		primes := tt.phi.PrimeImplicants()
+		if !primes.DeepSortedEquals(tt.primes) {
+			t.Errorf("Prime implicants of %s: expected %s, got %s", tt.phi, tt.primes, primes)
+		}
+		irredundant := tt.phi.Irredundant()
+		if !irredundant.DeepSortedEquals(tt.irredundant) {
+			t.Errorf("Irredundant DNF of %s: expected %s, got %s", tt.phi, tt.irredundant, irredundant)
+		}
+	}
+}
+
+func TestIsTautology(t *testing.T) {
+	tests := []struct {
+		phi      br.ClauseSet
+		expected bool
+	}{
+		{br.ClauseSet{}, false},
+		{br.ClauseSet{{}}, true},
+		{br.ClauseSet{{0, 1}, {2}}, false},
+		{br.ClauseSet{{1, 2}, {-1}, {-2}}, true},
+		{br.ClauseSet{{1, 2}, {-1}, {-2, 3}}, false},
+	}
+	for _, tt := range tests {
+		if res := tt.phi.IsTautology(); res != tt.expected {
+			t.Errorf("IsTautology of %s: expected %v, got %v", tt.phi, tt.expected, res)
+		}
+	}
+}
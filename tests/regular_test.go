@@ -0,0 +1,120 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"math/rand"
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+func TestIsRegular(t *testing.T) {
+	// x1 ∨ x2 x3 ∨ x2 x4 x5, strength order is x1, x2, x3 and x4 = x5
+	phi := br.ClauseSet{{0}, {1, 2}, {1, 3, 4}}
+	order, witness := br.IsRegular(phi, 5)
+	if witness != nil {
+		t.Fatalf("Expected %s to be regular, got witness %s", phi, witness)
+	}
+	if len(order) != 5 || order[0] != 0 || order[1] != 1 || order[2] != 2 {
+		t.Errorf("Expected order starting with 0, 1, 2, got %v", order)
+	}
+}
+
+func TestIsNotRegular(t *testing.T) {
+	// x1 x2 ∨ x3 x4 ∨ x1 x3 x5 is not regular: in the first MTP x1 is
+	// stronger than x3 (x2 x3 is false), in the second MTP x3 is stronger
+	// than x1 (x1 x4 is false)
+	phi := br.ClauseSet{{0, 1}, {2, 3}, {0, 2, 4}}
+	order, witness := br.IsRegular(phi, 5)
+	if witness == nil {
+		t.Fatalf("Expected %s not to be regular, got order %v", phi, order)
+	}
+	for _, w := range []struct {
+		point         br.BooleanVector
+		first, second int
+	}{
+		{witness.FirstPoint, witness.First, witness.Second},
+		{witness.SecondPoint, witness.Second, witness.First},
+	} {
+		if w.point[w.first] || !w.point[w.second] || !phi.EvalDNF(w.point) {
+			t.Errorf("Invalid witness %s", witness)
+			continue
+		}
+		swapped := w.point.Clone()
+		swapped[w.first], swapped[w.second] = true, false
+		if phi.EvalDNF(swapped) {
+			t.Errorf("Swapping x%d and x%d in %s yields a true point", w.first+1, w.second+1, w.point)
+		}
+	}
+}
+
+// atLeastAsStrong tests by brute force if i is at least as strong as j in ϕ.
+func atLeastAsStrong(phi br.ClauseSet, nbvar, i, j int) bool {
+	point := br.NewBooleanVector(nbvar)
+	for x := 0; x < 1<<uint(nbvar); x++ {
+		for v := 0; v < nbvar; v++ {
+			point[v] = x&(1<<uint(v)) != 0
+		}
+		if point[i] || !point[j] || !phi.EvalDNF(point) {
+			continue
+		}
+		point[i], point[j] = true, false
+		if !phi.EvalDNF(point) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIsRegularRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for n := 0; n < 500; n++ {
+		nbvar := 2 + r.Intn(5)
+		var phi br.ClauseSet
+		for c := 0; c < 1+r.Intn(5); c++ {
+			var clause br.Clause
+			for v := 0; v < nbvar; v++ {
+				if r.Intn(2) == 0 {
+					clause = append(clause, v)
+				}
+			}
+			if len(clause) > 0 {
+				phi = append(phi, clause)
+			}
+		}
+		regular := true
+		for i := 0; i < nbvar && regular; i++ {
+			for j := i + 1; j < nbvar; j++ {
+				if !atLeastAsStrong(phi, nbvar, i, j) && !atLeastAsStrong(phi, nbvar, j, i) {
+					regular = false
+					break
+				}
+			}
+		}
+		order, witness := br.IsRegular(phi, nbvar)
+		if regular != (witness == nil) {
+			t.Errorf("Expected regular = %v for %s, got witness %v", regular, phi, witness)
+			continue
+		}
+		for k := 1; k < len(order); k++ {
+			if !atLeastAsStrong(phi, nbvar, order[k-1], order[k]) {
+				t.Errorf("Wrong order %v for %s: x%d is not at least as strong as x%d",
+					order, phi, order[k-1]+1, order[k]+1)
+				break
+			}
+		}
+	}
+}
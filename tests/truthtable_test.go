@@ -0,0 +1,142 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	br "github.com/FabianWe/boolrecognition"
+)
+
+func TestEval(t *testing.T) {
+	// x1 x2 ∨ x3
+	phi := br.ClauseSet{{0, 1}, {2}}
+	tests := []struct {
+		point    br.BooleanVector
+		dnf, cnf bool
+	}{
+		{br.BooleanVector{true, true, false}, true, false},
+		{br.BooleanVector{true, false, true}, true, true},
+		{br.BooleanVector{true, false, false}, false, false},
+		{br.BooleanVector{false, false, false}, false, false},
+	}
+	for _, tt := range tests {
+		if res := phi.EvalDNF(tt.point); res != tt.dnf {
+			t.Errorf("Expected %v for DNF %s on %s, got %v", tt.dnf, phi, tt.point, res)
+		}
+		if res := phi.EvalCNF(tt.point); res != tt.cnf {
+			t.Errorf("Expected %v for CNF %s on %s, got %v", tt.cnf, phi, tt.point, res)
+		}
+	}
+}
+
+func TestTruthTable(t *testing.T) {
+	for _, phi := range []br.ClauseSet{
+		{},
+		{{}},
+		{{0, 1}, {2}},
+		{{0, 1}, {2, 3}},
+		{{0, 1}, {0, 2, 3}, {1, 2}},
+	} {
+		table := br.DNFTruthTable(phi, 4)
+		dual, err := phi.Dualize(4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !table.Equals(br.CNFTruthTable(dual, 4)) {
+			t.Errorf("DNF %s and its CNF %s have different truth tables", phi, dual)
+		}
+		if count := phi.CountModels(4); int64(table.Count()) != count.Int64() {
+			t.Errorf("Expected %s true points for DNF %s, got %d", count, phi, table.Count())
+		}
+		if !table.IsMonotone() {
+			t.Errorf("Truth table of DNF %s is not monotone", phi)
+		}
+		positive, err := table.ToPositiveDNF()
+		if err != nil {
+			t.Errorf("Expected no error for DNF %s, got %v", phi, err)
+		} else if !positive.DeepSortedEquals(phi.Minimize()) {
+			t.Errorf("Expected minimal DNF %s, got %s", phi.Minimize(), positive)
+		}
+		if !br.DNFTruthTable(phi, 4).Equals(table) || br.DNFTruthTable(phi, 5).Equals(table) {
+			t.Errorf("Equals is wrong for DNF %s", phi)
+		}
+	}
+}
+
+func TestTruthTableToDNF(t *testing.T) {
+	// x1 ⊕ x2
+	xor := br.NewTruthTableFunc(2, func(point br.BooleanVector) bool {
+		return point[0] != point[1]
+	})
+	if xor.IsMonotone() {
+		t.Error("XOR is not monotone")
+	}
+	if _, err := xor.ToPositiveDNF(); err != br.ErrNotMonotone {
+		t.Errorf("Expected ErrNotMonotone, got %v", err)
+	}
+	expected := br.ClauseSet{{-2, 1}, {-1, 2}}
+	if res := xor.ToDNF(); !res.DeepSortedEquals(expected) {
+		t.Errorf("Expected DNF %v, got %v", expected, res)
+	}
+	// x1 x2 ∨ ¬x1 x3 ∨ x2 x3, the last clause is redundant
+	f := br.NewTruthTableFunc(3, func(point br.BooleanVector) bool {
+		return (point[0] && point[1]) || (!point[0] && point[2])
+	})
+	expected = br.ClauseSet{{1, 2}, {-1, 3}}
+	if res := f.ToDNF(); !res.DeepSortedEquals(expected) {
+		t.Errorf("Expected DNF %v, got %v", expected, res)
+	}
+	// monotone functions give a positive DNF
+	phi := br.ClauseSet{{0, 1}, {0, 2, 3}, {1, 2}}
+	expected = br.ClauseSet{{1, 2}, {1, 3, 4}, {2, 3}}
+	if res := br.DNFTruthTable(phi, 4).ToDNF(); !res.DeepSortedEquals(expected) {
+		t.Errorf("Expected DNF %v, got %v", expected, res)
+	}
+	// the DNF must have the same truth table again
+	for _, table := range []*br.TruthTable{xor, f, br.DNFTruthTable(phi, 4),
+		br.DNFTruthTable(br.ClauseSet{}, 2), br.DNFTruthTable(br.ClauseSet{{}}, 2)} {
+		res := table.ToDNF()
+		if !br.GeneralDNFTruthTable(res, table.NbVar).Equals(table) {
+			t.Errorf("DNF %v has a different truth table", res)
+		}
+	}
+}
+
+func TestEvalGeneral(t *testing.T) {
+	// x1 ¬x2 ∨ ¬x3
+	phi := br.ClauseSet{{1, -2}, {-3}}
+	tests := []struct {
+		point    br.BooleanVector
+		dnf, cnf bool
+	}{
+		{br.BooleanVector{true, false, false}, true, true},
+		{br.BooleanVector{true, false, true}, true, false},
+		{br.BooleanVector{false, true, false}, true, false},
+		{br.BooleanVector{false, false, true}, false, false},
+		{br.BooleanVector{true, true, true}, false, false},
+	}
+	for _, tt := range tests {
+		if res := phi.EvalGeneralDNF(tt.point); res != tt.dnf {
+			t.Errorf("Expected %v for DNF %v on %s, got %v", tt.dnf, phi, tt.point, res)
+		}
+		if res := phi.EvalGeneralCNF(tt.point); res != tt.cnf {
+			t.Errorf("Expected %v for CNF %v on %s, got %v", tt.cnf, phi, tt.point, res)
+		}
+	}
+	if !br.GeneralDNFTruthTable(br.ClauseSet{{1, 2}, {1, 3, 4}, {2, 3}}, 4).Equals(br.DNFTruthTable(br.ClauseSet{{0, 1}, {0, 2, 3}, {1, 2}}, 4)) {
+		t.Error("Positive general DNF and positive DNF have different truth tables")
+	}
+}
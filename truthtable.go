@@ -0,0 +1,354 @@
+// Copyright 2017 Fabian Wenzelmann <fabianwen@posteo.eu>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boolrecognition
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// EvalDNF evaluates the positive DNF ϕ on the point, i.e. it returns true iff
+// there is a clause s.t. all variables in the clause are true in the point.
+//
+// Variables start with 0 and must be < len(point). For DNFs with negative
+// literals see EvalGeneralDNF.
+func (phi ClauseSet) EvalDNF(point BooleanVector) bool {
+	for _, clause := range phi {
+		clauseTrue := true
+		for _, v := range clause {
+			if !point[v] {
+				clauseTrue = false
+				break
+			}
+		}
+		if clauseTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// EvalCNF evaluates the positive CNF ϕ on the point, i.e. it returns true iff
+// each clause contains a variable that is true in the point.
+//
+// Variables start with 0 and must be < len(point). For CNFs with negative
+// literals see EvalGeneralCNF.
+func (phi ClauseSet) EvalCNF(point BooleanVector) bool {
+	for _, clause := range phi {
+		clauseTrue := false
+		for _, v := range clause {
+			if point[v] {
+				clauseTrue = true
+				break
+			}
+		}
+		if !clauseTrue {
+			return false
+		}
+	}
+	return true
+}
+
+// EvalGeneralDNF evaluates the general DNF ϕ on the point, i.e. it returns
+// true iff there is a clause s.t. all literals in the clause are true in the
+// point.
+//
+// The literals are represented as in DIMACS: the variable v (starting with 0)
+// is represented by v + 1 and ¬v by -(v + 1), so |l| must be ≤ len(point).
+func (phi ClauseSet) EvalGeneralDNF(point BooleanVector) bool {
+	for _, clause := range phi {
+		clauseTrue := true
+		for _, l := range clause {
+			if !evalLiteral(point, l) {
+				clauseTrue = false
+				break
+			}
+		}
+		if clauseTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// EvalGeneralCNF evaluates the general CNF ϕ on the point, i.e. it returns
+// true iff each clause contains a literal that is true in the point.
+//
+// The literals are represented as in EvalGeneralDNF.
+func (phi ClauseSet) EvalGeneralCNF(point BooleanVector) bool {
+	for _, clause := range phi {
+		clauseTrue := false
+		for _, l := range clause {
+			if evalLiteral(point, l) {
+				clauseTrue = true
+				break
+			}
+		}
+		if !clauseTrue {
+			return false
+		}
+	}
+	return true
+}
+
+// evalLiteral returns the value of the DIMACS literal l in the point.
+func evalLiteral(point BooleanVector, l int) bool {
+	if l < 0 {
+		return !point[-l-1]
+	}
+	return point[l-1]
+}
+
+// TruthTable is the truth table of a Boolean function with NbVar variables.
+//
+// The values are stored bit-packed: The point with index i (0 ≤ i < 2^NbVar)
+// is the point in which variable v is true iff bit v of i is set. So the
+// table requires 2^NbVar bits and should only be used for a small number of
+// variables (say ≤ 20), for example to compute the ground truth in tests.
+type TruthTable struct {
+	NbVar int
+	bits  []uint64
+}
+
+// NewTruthTable returns a new truth table with nbvar variables where all
+// values are false.
+func NewTruthTable(nbvar int) *TruthTable {
+	size := (uint64(1)<<uint(nbvar) + 63) / 64
+	return &TruthTable{NbVar: nbvar, bits: make([]uint64, size)}
+}
+
+// NewTruthTableFunc returns the truth table of f with nbvar variables, f is
+// called for each point. The point passed to f must not be stored, it is
+// changed after f returns.
+func NewTruthTableFunc(nbvar int, f func(point BooleanVector) bool) *TruthTable {
+	res := NewTruthTable(nbvar)
+	point := NewBooleanVector(nbvar)
+	size := res.Size()
+	for i := uint64(0); i < size; i++ {
+		for v := range point {
+			point[v] = i&(1<<uint(v)) != 0
+		}
+		if f(point) {
+			res.Set(i, true)
+		}
+	}
+	return res
+}
+
+// DNFTruthTable returns the truth table of the positive DNF ϕ with nbvar
+// variables.
+func DNFTruthTable(phi ClauseSet, nbvar int) *TruthTable {
+	return NewTruthTableFunc(nbvar, phi.EvalDNF)
+}
+
+// CNFTruthTable returns the truth table of the positive CNF ϕ with nbvar
+// variables.
+func CNFTruthTable(phi ClauseSet, nbvar int) *TruthTable {
+	return NewTruthTableFunc(nbvar, phi.EvalCNF)
+}
+
+// GeneralDNFTruthTable returns the truth table of the general DNF ϕ with
+// nbvar variables, see EvalGeneralDNF.
+func GeneralDNFTruthTable(phi ClauseSet, nbvar int) *TruthTable {
+	return NewTruthTableFunc(nbvar, phi.EvalGeneralDNF)
+}
+
+// GeneralCNFTruthTable returns the truth table of the general CNF ϕ with
+// nbvar variables, see EvalGeneralCNF.
+func GeneralCNFTruthTable(phi ClauseSet, nbvar int) *TruthTable {
+	return NewTruthTableFunc(nbvar, phi.EvalGeneralCNF)
+}
+
+// Size returns the number of points in the table (2^NbVar).
+func (t *TruthTable) Size() uint64 {
+	return uint64(1) << uint(t.NbVar)
+}
+
+// Get returns the value of the point with the given index.
+func (t *TruthTable) Get(index uint64) bool {
+	return t.bits[index/64]&(1<<(index%64)) != 0
+}
+
+// Set sets the value of the point with the given index.
+func (t *TruthTable) Set(index uint64, val bool) {
+	if val {
+		t.bits[index/64] |= 1 << (index % 64)
+	} else {
+		t.bits[index/64] &^= 1 << (index % 64)
+	}
+}
+
+// Eval returns the value of the point, the point must have NbVar entries.
+func (t *TruthTable) Eval(point BooleanVector) bool {
+	var index uint64
+	for v, val := range point {
+		if val {
+			index |= 1 << uint(v)
+		}
+	}
+	return t.Get(index)
+}
+
+// Count returns the number of true points.
+func (t *TruthTable) Count() int {
+	res := 0
+	for _, word := range t.bits {
+		res += bits.OnesCount64(word)
+	}
+	return res
+}
+
+// Equals checks if both tables have the same number of variables and
+// represent the same function.
+func (t *TruthTable) Equals(other *TruthTable) bool {
+	if t.NbVar != other.NbVar {
+		return false
+	}
+	for i, word := range t.bits {
+		if word != other.bits[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsMonotone checks if the function is monotone, i.e. if a point is true each
+// point we get by setting a variable to true is true as well.
+func (t *TruthTable) IsMonotone() bool {
+	size := t.Size()
+	for i := uint64(0); i < size; i++ {
+		if !t.Get(i) {
+			continue
+		}
+		for v := 0; v < t.NbVar; v++ {
+			if !t.Get(i | (1 << uint(v))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ErrNotMonotone is returned by TruthTable.ToPositiveDNF if the function is
+// not monotone.
+var ErrNotMonotone = errors.New("Function is not monotone")
+
+// ToPositiveDNF returns the minimal positive DNF of a monotone function
+// (variables start with 0), i.e. the clauses are the minimal true points.
+// The clauses are ordered as described in Minimize.
+//
+// If the function is not monotone ErrNotMonotone is returned, see ToDNF for
+// general functions.
+func (t *TruthTable) ToPositiveDNF() (ClauseSet, error) {
+	if !t.IsMonotone() {
+		return nil, ErrNotMonotone
+	}
+	res := NewClauseSet(0)
+	size := t.Size()
+	for i := uint64(0); i < size; i++ {
+		if !t.Get(i) {
+			continue
+		}
+		// i is a minimal true point iff all points below are false
+		minimal := true
+		for v := 0; v < t.NbVar; v++ {
+			if i&(1<<uint(v)) != 0 && t.Get(i&^(1<<uint(v))) {
+				minimal = false
+				break
+			}
+		}
+		if minimal {
+			clause := NewClause(bits.OnesCount64(i))
+			for v := 0; v < t.NbVar; v++ {
+				if i&(1<<uint(v)) != 0 {
+					clause = append(clause, v)
+				}
+			}
+			res = append(res, clause)
+		}
+	}
+	return res.Minimize(), nil
+}
+
+// ToDNF returns an irredundant DNF of the function that consists of prime
+// implicants (see Irredundant). The literals are represented as in DIMACS:
+// the variable v (starting with 0) is represented by v + 1 and ¬v by -(v + 1).
+// GeneralDNFTruthTable returns the truth table of the result again.
+//
+// The prime implicants are computed recursively: The prime implicants of f
+// are the prime implicants of f(x = 0) ∧ f(x = 1), ¬x ⋅ p for each prime
+// implicant p of f(x = 0) and x ⋅ p for each prime implicant p of f(x = 1)
+// that is not a prime implicant of the conjunction.
+// This requires up to 3^NbVar steps, so it should only be used for a small
+// number of variables. For monotone functions ToPositiveDNF is much faster.
+func (t *TruthTable) ToDNF() ClauseSet {
+	values := make([]bool, t.Size())
+	for i := range values {
+		values[i] = t.Get(uint64(i))
+	}
+	return truthTablePrimes(values, t.NbVar).Irredundant()
+}
+
+// truthTablePrimes computes the prime implicants of the function with the
+// given values and nbvar variables, the clauses in the result are sorted.
+// The highest variable is used for the Shannon expansion because the values
+// for x = 0 and x = 1 are the two halves of values.
+func truthTablePrimes(values []bool, nbvar int) ClauseSet {
+	allTrue, allFalse := true, true
+	for _, val := range values {
+		if val {
+			allFalse = false
+		} else {
+			allTrue = false
+		}
+	}
+	switch {
+	case allFalse:
+		return NewClauseSet(0)
+	case allTrue:
+		return ClauseSet{NewClause(0)}
+	}
+	half := len(values) / 2
+	f0, f1 := values[:half], values[half:]
+	conj := make([]bool, half)
+	for i := range conj {
+		conj[i] = f0[i] && f1[i]
+	}
+	literal := nbvar
+	primesConj := truthTablePrimes(conj, nbvar-1)
+	res := NewClauseSet(len(primesConj))
+	res = append(res, primesConj...)
+	for _, p := range truthTablePrimes(f0, nbvar-1) {
+		if !clauseIn(primesConj, p) {
+			res = append(res, append(p.Normalize(), -literal).Normalize())
+		}
+	}
+	for _, p := range truthTablePrimes(f1, nbvar-1) {
+		if !clauseIn(primesConj, p) {
+			res = append(res, append(p.Normalize(), literal).Normalize())
+		}
+	}
+	return res
+}
+
+// clauseIn checks if ϕ contains the clause c, all clauses must be sorted.
+func clauseIn(phi ClauseSet, c Clause) bool {
+	for _, other := range phi {
+		if equalSortedClause(other, c) {
+			return true
+		}
+	}
+	return false
+}